@@ -0,0 +1,125 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command confdoc generates the reference of the variables read by each
+// config struct, either as a Markdown document or a .env file example.
+// Config structs are discovered in the packages matching the given
+// patterns (./... by default), i.e. the ones passed to conftemplate.Read,
+// ConfParser.ParseConf and NewWatcher. Doc comments of their fields
+// are read from the source of the same packages.
+//
+//	go run ./cmd/confdoc -format markdown -out CONFIG.md
+//	go run ./cmd/confdoc -format env -out .env.example ./internal/...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+)
+
+// writers maps each output format to its writer
+var writers = map[string]func(io.Writer, ...*config.ReferenceSection) error{
+	"markdown": config.WriteMarkdownReference,
+	"env":      config.WriteEnvExample,
+}
+
+// sectionOf returns the reference section of c, where
+// types and docs are the ones declared in the source
+func (d *discovery) sectionOf(
+	c *configStruct, docs map[token.Pos]string,
+) (*config.ReferenceSection, error) {
+	sType, err := d.reflectStruct(c)
+	if err != nil {
+		return nil, err
+	}
+
+	section, err := config.NewReferenceSection(c.title(), reflect.New(sType).Interface(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]*types.Var)
+	fieldsOf(c.srt, "", fields)
+
+	for _, v := range section.Variables {
+		if field, ok := fields[v.FieldPath]; ok {
+			v.Type = types.TypeString(field.Type(), qualifier)
+			v.Doc = docs[field.Pos()]
+		}
+	}
+
+	return section, nil
+}
+
+func run(format, out string, patterns ...string) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unknown format %v; accepted formats: markdown, env", format)
+	}
+
+	d, err := discover(patterns...)
+	if err != nil {
+		return err
+	}
+
+	docs := d.fieldDocs()
+
+	var sections []*config.ReferenceSection
+
+	for _, c := range d.configs() {
+		section, err := d.sectionOf(c, docs)
+		if err != nil {
+			return fmt.Errorf("invalid config struct %v: %w", c.named, err)
+		}
+
+		sections = append(sections, section)
+	}
+
+	w := os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		w = f
+	}
+
+	return write(w, sections...)
+}
+
+func main() {
+	format := flag.String("format", "markdown", "output format: markdown or env")
+	out := flag.String("out", "", "output file (stdout by default)")
+	flag.Parse()
+
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	if err := run(*format, *out, patterns...); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "confdoc: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gotypes"
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	configPath       = "github.com/franciscosbf/micro-dwarf/internal/config"
+	conftemplatePath = "github.com/franciscosbf/micro-dwarf/internal/conftemplate"
+)
+
+// loadMode contains what is needed to discover config structs. Dependencies
+// are loaded from source too, since they may declare embedded structs
+const loadMode = packages.NeedName | packages.NeedImports | packages.NeedDeps |
+	packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+
+// opaque replaces types parsed by unmarshalers or by registered
+// converters, whose values are only known when the program runs
+type opaque string
+
+func (*opaque) UnmarshalConfig(string) error { return nil }
+
+// builtinTypes maps the qualified name of each type with
+// a builtin converter of the config parser to its type
+var builtinTypes = func() map[string]reflect.Type {
+	m := make(map[string]reflect.Type)
+	for _, t := range gen.LoaderTypes() {
+		m[gotypes.TypeKey(t)] = t
+	}
+
+	return m
+}()
+
+// configStruct is a config struct found in the loaded packages
+type configStruct struct {
+	named *types.Named
+	srt   *types.Struct
+}
+
+// title returns the name of the struct without the suffix Config
+func (c *configStruct) title() string {
+	name := c.named.Obj().Name()
+	if title := strings.TrimSuffix(name, "Config"); title != "" {
+		return title
+	}
+
+	return name
+}
+
+// discovery contains the config structs of the loaded packages, i.e. the
+// ones passed to conftemplate.Read, ConfParser.ParseConf and NewWatcher
+type discovery struct {
+	pkgs       []*packages.Package
+	structs    map[*types.TypeName]*configStruct
+	registered map[string]bool
+}
+
+// discover loads the packages matching patterns and finds their config structs
+func discover(patterns ...string) (*discovery, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: loadMode}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("couldn't load packages %v", strings.Join(patterns, " "))
+	}
+
+	d := &discovery{
+		pkgs:       pkgs,
+		structs:    make(map[*types.TypeName]*configStruct),
+		registered: make(map[string]bool),
+	}
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					d.inspectCall(pkg.TypesInfo, call)
+				}
+
+				return true
+			})
+		}
+	}
+
+	return d, nil
+}
+
+// calleeOf returns the function called by call if it's
+// declared in the package pkgPath. Otherwise, returns nil
+func calleeOf(info *types.Info, call *ast.CallExpr, pkgPath string) *types.Func {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return nil
+	}
+
+	return fn
+}
+
+// typeArgOf returns the type argument of the call of a generic function
+func typeArgOf(info *types.Info, call *ast.CallExpr) types.Type {
+	fun := ast.Unparen(call.Fun)
+
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	}
+
+	instance, ok := info.Instances[id]
+	if !ok || instance.TypeArgs.Len() == 0 {
+		return nil
+	}
+
+	return instance.TypeArgs.At(0)
+}
+
+// inspectCall records the config struct parsed by call, if
+// any, as well as the types of registered converters
+func (d *discovery) inspectCall(info *types.Info, call *ast.CallExpr) {
+	if fn := calleeOf(info, call, conftemplatePath); fn != nil {
+		if fn.Name() == "Read" && len(call.Args) >= 2 {
+			d.add(info.TypeOf(call.Args[1]))
+		}
+
+		return
+	}
+
+	fn := calleeOf(info, call, configPath)
+	if fn == nil {
+		return
+	}
+
+	switch fn.Name() {
+	case "ParseConf":
+		if len(call.Args) == 1 {
+			d.add(info.TypeOf(call.Args[0]))
+		}
+	case "NewWatcher":
+		if t := typeArgOf(info, call); t != nil {
+			d.add(types.NewPointer(t))
+		}
+	case "RegisterConverter":
+		if t := typeArgOf(info, call); t != nil {
+			d.registered[types.TypeString(t, nil)] = true
+		}
+	}
+}
+
+// add records t if it's a pointer to a named struct
+func (d *discovery) add(t types.Type) {
+	ptr, ok := types.Unalias(t).(*types.Pointer)
+	if !ok {
+		return
+	}
+
+	named, ok := types.Unalias(ptr.Elem()).(*types.Named)
+	if !ok {
+		return
+	}
+
+	if srt, ok := named.Underlying().(*types.Struct); ok {
+		d.structs[named.Obj()] = &configStruct{named: named, srt: srt}
+	}
+}
+
+// configs returns the config structs, sorted by package and name
+func (d *discovery) configs() []*configStruct {
+	configs := make([]*configStruct, 0, len(d.structs))
+	for _, c := range d.structs {
+		configs = append(configs, c)
+	}
+
+	sort.Slice(configs, func(i, j int) bool {
+		a, b := configs[i].named.Obj(), configs[j].named.Obj()
+		if a.Pkg().Path() != b.Pkg().Path() {
+			return a.Pkg().Path() < b.Pkg().Path()
+		}
+
+		return a.Name() < b.Name()
+	})
+
+	return configs
+}
+
+// resolve maps builtin types to themselves, while the ones
+// parsed by unmarshalers or registered converters are opaque
+func (d *discovery) resolve(t types.Type, _ string) (reflect.Type, bool, error) {
+	key := types.TypeString(t, nil)
+
+	if rType, ok := builtinTypes[key]; ok {
+		return rType, true, nil
+	}
+
+	if !d.registered[key] && !gotypes.IsUnmarshaler(t) {
+		return nil, false, nil
+	}
+
+	if _, ok := t.(*types.Pointer); ok {
+		return reflect.TypeOf((*opaque)(nil)), true, nil
+	}
+
+	return reflect.TypeOf(opaque("")), true, nil
+}
+
+// reflectStruct returns the equivalent struct type of c
+func (d *discovery) reflectStruct(c *configStruct) (reflect.Type, error) {
+	return gotypes.StructOf(c.srt, "", d.resolve)
+}
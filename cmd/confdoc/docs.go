@@ -0,0 +1,111 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/packages"
+	"strings"
+)
+
+// commentText returns the field doc comment, falling
+// back to the comment in the same line of the field
+func commentText(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+
+	return ""
+}
+
+// embeddedName returns the type name of an embedded field, e.g. Foo of *pkg.Foo
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+
+	return nil
+}
+
+// fieldDocs returns the comment of each struct field declared in the
+// loaded packages and their dependencies, identified by the position
+// of its name, as given by types.Var.Pos
+func (d *discovery) fieldDocs() map[token.Pos]string {
+	comments := make(map[token.Pos]string)
+
+	packages.Visit(d.pkgs, nil, func(pkg *packages.Package) {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				srt, ok := n.(*ast.StructType)
+				if !ok {
+					return true
+				}
+
+				for _, field := range srt.Fields.List {
+					for _, ident := range field.Names {
+						comments[ident.Pos()] = commentText(field)
+					}
+
+					// Embedded fields are identified by their type name
+					if ident := embeddedName(field.Type); field.Names == nil && ident != nil {
+						comments[ident.Pos()] = commentText(field)
+					}
+				}
+
+				return true
+			})
+		}
+	})
+
+	return comments
+}
+
+// fieldsOf maps the path of each field of srt, including the ones
+// of nested structs, to the field, e.g. Tls.Cert. Paths are
+// prefixed by path, like the field paths of the config parser
+func fieldsOf(srt *types.Struct, path string, fields map[string]*types.Var) {
+	for i := 0; i < srt.NumFields(); i++ {
+		field := srt.Field(i)
+		fieldPath := path + field.Name()
+
+		fields[fieldPath] = field
+
+		if nested, ok := field.Type().Underlying().(*types.Struct); ok {
+			fieldsOf(nested, fieldPath+".", fields)
+		}
+	}
+}
+
+// qualifier qualifies types by their package name, e.g. time.Duration
+func qualifier(pkg *types.Package) string {
+	return pkg.Name()
+}
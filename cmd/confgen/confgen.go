@@ -0,0 +1,110 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command confgen generates reflection-free loaders of config structs,
+// i.e. the methods Load and LoadWith of each struct, which fill it like
+// config.ConfParser.ParseConf. Tags are validated on generation, so
+// invalid ones fail before the program runs. It's meant to be run by
+// go generate in the package that declares the structs:
+//
+//	//go:generate go run github.com/franciscosbf/micro-dwarf/cmd/confgen -type ServiceConfig
+//
+// Only the builtin types are supported, see gen.LoaderTypes, since
+// custom converters and unmarshalers are registered at runtime.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultOutput returns the name of the generated file when
+// it isn't given, which is named after the first struct
+func defaultOutput(typeNames []string) string {
+	return strings.ToLower(typeNames[0]) + "_loader.go"
+}
+
+// generate writes the loaders of the structs typeNames, declared in
+// the package of dir, to the file output (relative to dir, if not absolute)
+func generate(dir string, typeNames []string, output string) error {
+	if output == "" {
+		output = defaultOutput(typeNames)
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return fmt.Errorf("couldn't load package: %w", err)
+	}
+
+	structs := make([]loaderStruct, 0, len(typeNames))
+
+	for _, name := range typeNames {
+		sType, err := reflectStruct(pkg, name)
+		if err != nil {
+			return err
+		}
+
+		structs = append(structs, loaderStruct{name: name, sType: sType})
+	}
+
+	var src bytes.Buffer
+	if err := writeLoaders(&src, pkg.Name(), structs...); err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, src.Bytes(), 0o644)
+}
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of config struct names (required)")
+	output := flag.String("output", "", "output file name (<type>_loader.go by default)")
+	flag.Parse()
+
+	names, ok := splitNames(*typeNames)
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := generate(".", names, *output); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "confgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// splitNames returns the struct names of the flag type. Returns
+// false if there isn't any name or some of them is empty
+func splitNames(typeNames string) ([]string, bool) {
+	if typeNames == "" {
+		return nil, false
+	}
+
+	names := strings.Split(typeNames, ",")
+	for i, name := range names {
+		if names[i] = strings.TrimSpace(name); names[i] == "" {
+			return nil, false
+		}
+	}
+
+	return names, true
+}
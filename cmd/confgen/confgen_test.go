@@ -0,0 +1,108 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedLoadersUpToDate(t *testing.T) {
+	testBattery := []struct {
+		dir       string
+		typeNames []string
+		output    string
+	}{
+		{"../../internal/config/loadertest", []string{"Fixture", "Minimal"}, "fixture_loader.go"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.output, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), pair.output)
+
+			if err := generate(pair.dir, pair.typeNames, output); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			generated, _ := os.ReadFile(output)
+			committed, err := os.ReadFile(filepath.Join(pair.dir, pair.output))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !bytes.Equal(generated, committed) {
+				t.Errorf("%v is outdated, run go generate", pair.output)
+			}
+		})
+	}
+}
+
+func TestGenerateInvalidStructs(t *testing.T) {
+	testBattery := []struct {
+		typeName string
+		expected string
+	}{
+		{"MissingName", "missing tag name in struct field Host"},
+		{"Unsupported", "chan string"},
+		{"Private", "field timeout is private"},
+		{"PrivateEmbedded", "field creds is private"},
+		{"Unmarshaler", "can only be parsed by an unmarshaler"},
+		{"NotStruct", "isn't a struct"},
+		{"Unknown", "Unknown"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.typeName, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "loader.go")
+
+			err := generate("testdata/invalid", []string{pair.typeName}, output)
+			if err == nil || !strings.Contains(err.Error(), pair.expected) {
+				t.Errorf("Expecting error containing %q, got %v", pair.expected, err)
+			}
+
+			if _, err := os.Stat(output); err == nil {
+				t.Errorf("Unexpected file %v", output)
+			}
+		})
+	}
+}
+
+func TestSplitNames(t *testing.T) {
+	testBattery := []struct {
+		typeNames string
+		expected  []string
+	}{
+		{"A", []string{"A"}},
+		{"A, B", []string{"A", "B"}},
+		{"", nil},
+		{"A,,B", nil},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.typeNames, func(t *testing.T) {
+			names, ok := splitNames(pair.typeNames)
+			if ok != (pair.expected != nil) || strings.Join(names, ",") != strings.Join(pair.expected, ",") {
+				t.Errorf("Expecting %v, got %v", pair.expected, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package invalid declares config structs that can't have generated loaders
+package invalid
+
+import "time"
+
+type MissingName struct {
+	Host string `required:"yes"`
+}
+
+type Unsupported struct {
+	Events chan string `name:"EVENTS"`
+}
+
+type Private struct {
+	timeout time.Duration `name:"TIMEOUT"`
+}
+
+type creds struct {
+	User string `name:"USER"`
+}
+
+type PrivateEmbedded struct {
+	X int `name:"X"`
+	creds
+}
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	return nil
+}
+
+type Unmarshaler struct {
+	Level Level `name:"LEVEL"`
+}
+
+type NotStruct []string
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gotypes"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+)
+
+// loadPackage type-checks the non-test source files of the package of
+// dir, ignoring output. Type errors are ignored as well, since the
+// package might reference loaders that aren't generated yet
+func loadPackage(dir, output string) (*types.Package, error) {
+	bPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for _, name := range bPkg.GoFiles {
+		path := filepath.Join(dir, name)
+		if path == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+
+	pkg, _ := conf.Check(bPkg.ImportPath, fset, files, nil)
+
+	return pkg, nil
+}
+
+// loaderTypes maps the qualified name of each type
+// supported by generated loaders, see gotypes.TypeKey
+var loaderTypes = func() map[string]reflect.Type {
+	m := make(map[string]reflect.Type)
+	for _, t := range gen.LoaderTypes() {
+		m[gotypes.TypeKey(t)] = t
+	}
+
+	return m
+}()
+
+// resolveLoaderType resolves the types supported by generated loaders.
+// Other named types need a converter or an unmarshaler, which aren't
+// available without running the program
+func resolveLoaderType(t types.Type, path string) (reflect.Type, bool, error) {
+	if rType, ok := loaderTypes[types.TypeString(t, nil)]; ok {
+		return rType, true, nil
+	}
+
+	if gotypes.IsUnmarshaler(t) {
+		return nil, false, fmt.Errorf(
+			"struct field %v contains type %v, which can only be parsed by an unmarshaler", path, t)
+	}
+
+	return nil, false, nil
+}
+
+// reflectStruct returns the equivalent struct type of the struct named name
+func reflectStruct(pkg *types.Package, name string) (reflect.Type, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %v not found in package %v", name, pkg.Name())
+	}
+
+	srt, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %v isn't a struct", name)
+	}
+
+	sType, err := gotypes.StructOf(srt, "", resolveLoaderType)
+	if err != nil {
+		return nil, fmt.Errorf("struct %v: %w", name, err)
+	}
+
+	return sType, nil
+}
@@ -0,0 +1,487 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"go/format"
+	"io"
+	"math"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// unsupportedFieldError represents a struct field
+// that can't be filled by a generated loader
+type unsupportedFieldError struct {
+	fieldName string
+	reason    string
+}
+
+func (e *unsupportedFieldError) Error() string {
+	return fmt.Sprintf(
+		"struct field %v isn't supported by generated loaders: %v",
+		e.fieldName, e.reason)
+}
+
+// loaderStruct is a config struct whose loader is written by writeLoaders
+type loaderStruct struct {
+	name  string       // Name of the struct type in the generated package
+	sType reflect.Type // Equivalent struct type built with reflect.StructOf
+}
+
+// comparableLoaderTypes contains the non-basic types whose values
+// can be compared with == in conditions of required_if, i.e. the
+// ones where it's equivalent to reflect.DeepEqual
+var comparableLoaderTypes = map[reflect.Type]bool{
+	reflect.TypeOf(netip.Addr{}):   true,
+	reflect.TypeOf(netip.Prefix{}): true,
+}
+
+// loaderField describes how a generated loader fills a variable
+type loaderField struct {
+	spec   *config.FieldSpec
+	elemT  reflect.Type // Type of the value (or of each collection element)
+	parse  string       // Name of the parser of elemT
+	shape  string       // Function that assigns the parsed value, e.g. Parse
+	access string       // Expression of the field, e.g. c.Tls.Cert
+}
+
+// newLoaderField returns how spec is filled by a generated loader.
+// Returns unsupportedFieldError if its type isn't supported
+func newLoaderField(spec *config.FieldSpec) (*loaderField, error) {
+	f := &loaderField{spec: spec, elemT: spec.Type, shape: "Parse", access: "c." + spec.FieldPath}
+
+	unsupported := func(reason string) (*loaderField, error) {
+		return nil, &unsupportedFieldError{fieldName: spec.FieldPath, reason: reason}
+	}
+
+	switch {
+	case spec.Collection == reflect.Map && spec.Type.Key() != reflect.TypeOf(""):
+		return unsupported(fmt.Sprintf("map keys must be of type string, not %v", spec.Type.Key()))
+	case spec.Collection == reflect.Map:
+		f.elemT, f.shape = spec.Type.Elem(), "ParseMap"
+	case spec.Collection == reflect.Slice:
+		f.elemT, f.shape = spec.Type.Elem(), "ParseSlice"
+	case spec.Optional:
+		f.elemT, f.shape = spec.Type.Elem(), "ParseOptional"
+	}
+
+	if _, ok := gen.ParserName(f.elemT); spec.Collection != reflect.Invalid &&
+		f.elemT.Kind() == reflect.Pointer && !ok {
+		return unsupported("collections of optionals aren't supported")
+	}
+
+	parse, ok := gen.ParserName(f.elemT)
+	if !ok {
+		return unsupported(fmt.Sprintf(
+			"type %v is parsed by a registered converter or by an unmarshaler", f.elemT))
+	}
+	f.parse = parse
+
+	return f, nil
+}
+
+// parser returns the expression of the parser of f. If explicit,
+// generic parsers are instantiated with the elements type
+func (f *loaderField) parser(explicit bool) string {
+	expr := "gen." + f.parse
+
+	switch f.parse {
+	case "ParseSigned", "ParseUnsigned", "ParseFloat":
+		if explicit {
+			expr += "[" + f.elemT.Name() + "]"
+		}
+	}
+
+	return expr
+}
+
+// goLiteral returns the Go literal of val if it's a bool, a
+// string or a finite number, which is converted to its type
+func goLiteral(val reflect.Value) (string, bool) {
+	switch {
+	case val.Kind() == reflect.Bool:
+		return strconv.FormatBool(val.Bool()), true
+	case val.Kind() == reflect.String:
+		return strconv.Quote(val.String()), true
+	case val.CanInt():
+		return strconv.FormatInt(val.Int(), 10), true
+	case val.CanUint():
+		return strconv.FormatUint(val.Uint(), 10), true
+	case val.CanFloat() && !math.IsInf(val.Float(), 0) && !math.IsNaN(val.Float()):
+		return strconv.FormatFloat(val.Float(), 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// loaderWriter writes the loaders of a file
+type loaderWriter struct {
+	decls   bytes.Buffer
+	values  bytes.Buffer // Declarations of values without a literal
+	imports map[string]string
+}
+
+// lowerFirst returns name with the first letter in lower case
+func lowerFirst(name string) string {
+	for i, r := range name {
+		return string(unicode.ToLower(r)) + name[i+len(string(r)):]
+	}
+
+	return name
+}
+
+// valueOf returns the expression of val, converted from rawVal by
+// the parser of f. A package variable is declared if val doesn't
+// have a literal, named name
+func (w *loaderWriter) valueOf(f *loaderField, val reflect.Value, rawVal, name string) string {
+	if lit, ok := goLiteral(reflect.Indirect(val)); ok {
+		return lit
+	}
+
+	_, _ = fmt.Fprintf(&w.values, "%v = gen.MustParse(%v, %v)\n",
+		name, f.parser(true), strconv.Quote(rawVal))
+
+	return name
+}
+
+// writeBound writes the check of the bound key of f, where elem is
+// the expression of the value (or of each collection element)
+func (w *loaderWriter) writeBound(b io.Writer, f *loaderField, elem, key, prefix string, i int) {
+	bound, op, violation := f.spec.Min, "<", "MinViolation"
+	if key == "max" {
+		bound, op, violation = f.spec.Max, ">", "MaxViolation"
+	}
+
+	if bound == nil {
+		return
+	}
+
+	name := fmt.Sprintf("%v%v%v%v", prefix, strings.ToUpper(key[:1]), key[1:], i)
+	boundExpr := w.valueOf(f, bound.Value, bound.Raw, name)
+
+	cond := fmt.Sprintf("%v %v %v", elem, op, boundExpr)
+	if !isNumericKind(f.elemT.Kind()) {
+		cond = fmt.Sprintf("%v.Compare(%v) %v 0", elem, boundExpr, op)
+	}
+
+	_, _ = fmt.Fprintf(b, "if err == nil && %v {\nerr = gen.%v(%v, %v)\n}\n",
+		cond, violation, strconv.Quote(bound.Raw), elem)
+}
+
+// isNumericKind tells if values of kind can be compared with < and >
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeField writes the case of the variable at position i,
+// which converts its raw value and assigns it to the field
+func (w *loaderWriter) writeField(b io.Writer, f *loaderField, i int, prefix string) {
+	spec := f.spec
+
+	_, _ = fmt.Fprintf(b, "case %v: // %v\nval := %v\n", i, spec.Name, f.access)
+
+	switch f.shape {
+	case "ParseSlice", "ParseMap":
+		_, _ = fmt.Fprintf(b, "err := gen.%v(&val, rawVal, %v, %v)\n",
+			f.shape, strconv.Quote(spec.Separator), f.parser(false))
+	default:
+		_, _ = fmt.Fprintf(b, "err := gen.%v(&val, rawVal, %v)\n", f.shape, f.parser(false))
+	}
+
+	elem := "val"
+	if f.shape == "ParseOptional" {
+		elem = "*val"
+	}
+
+	if spec.HasLen {
+		length := fmt.Sprintf("len(%v)", elem)
+		if spec.Collection == reflect.Invalid {
+			w.imports["unicode/utf8"] = ""
+			length = fmt.Sprintf("utf8.RuneCountInString(%v)", elem)
+		}
+
+		_, _ = fmt.Fprintf(b, "if err == nil {\nerr = gen.CheckLen(%v, %v, %v)\n}\n",
+			length, spec.MinLen, spec.MaxLen)
+	}
+
+	if spec.Min != nil || spec.Max != nil {
+		if spec.Collection != reflect.Invalid {
+			_, _ = fmt.Fprintf(b, "for _, e := range val {\n")
+			elem = "e"
+		}
+
+		w.writeBound(b, f, elem, "min", prefix, i)
+		w.writeBound(b, f, elem, "max", prefix, i)
+
+		if spec.Collection != reflect.Invalid {
+			_, _ = fmt.Fprintf(b, "}\n")
+		}
+	}
+
+	if spec.Pattern != "" {
+		_, _ = fmt.Fprintf(b, "if err == nil {\nerr = %vSchema.Match(%v, rawVal)\n}\n", prefix, i)
+	}
+
+	_, _ = fmt.Fprintf(b, "if err == nil {\n%v = val\n}\n\nreturn err\n", f.access)
+}
+
+// holdsExpr returns the expression that tells if the condition at
+// position c of f holds. Returns unsupportedFieldError if the
+// type of its target can't be compared without reflection
+func (w *loaderWriter) holdsExpr(f *loaderField, c int, fields []*loaderField, name string) (string, error) {
+	condition := f.spec.Conditions[c]
+	target := fields[condition.Target]
+
+	kind := target.elemT.Kind()
+	comparable := isNumericKind(kind) || kind == reflect.Bool ||
+		kind == reflect.String || comparableLoaderTypes[target.elemT]
+
+	if target.shape != "Parse" && target.shape != "ParseOptional" || !comparable {
+		return "", &unsupportedFieldError{
+			fieldName: f.spec.FieldPath,
+			reason: fmt.Sprintf(
+				"required_if can't reference variable %v of type %v",
+				target.spec.Name, target.spec.Type),
+		}
+	}
+
+	expr := target.access
+	if target.shape == "ParseOptional" {
+		expr = "*" + expr
+	}
+
+	value := w.valueOf(target, condition.Value, f.spec.RequiredIf[c].Value, name)
+	switch value {
+	case "true":
+	case "false":
+		expr = "!" + expr
+	default:
+		expr = fmt.Sprintf("%v == %v", expr, value)
+	}
+
+	if target.shape == "ParseOptional" {
+		expr = fmt.Sprintf("%v != nil && %v", target.access, expr)
+	}
+
+	return expr, nil
+}
+
+// writeSpec writes the literal of spec
+func writeSpec(b io.Writer, spec *gen.VarSpec) {
+	quoted := func(values []string) string {
+		quotes := make([]string, len(values))
+		for i, value := range values {
+			quotes[i] = strconv.Quote(value)
+		}
+
+		return "[]string{" + strings.Join(quotes, ", ") + "}"
+	}
+
+	_, _ = fmt.Fprintf(b, "{\nName: %v,\nFieldPath: %v,\n",
+		strconv.Quote(spec.Name), strconv.Quote(spec.FieldPath))
+
+	if len(spec.Aliases) > 0 {
+		_, _ = fmt.Fprintf(b, "Aliases: %v,\n", quoted(spec.Aliases))
+	}
+	if spec.Required {
+		_, _ = fmt.Fprintf(b, "Required: true,\n")
+	}
+	if spec.Secret {
+		_, _ = fmt.Fprintf(b, "Secret: true,\n")
+	}
+	if spec.HasDefault {
+		_, _ = fmt.Fprintf(b, "Default: %v,\nHasDefault: true,\n", strconv.Quote(spec.Default))
+	}
+	if len(spec.Accepts) > 0 {
+		_, _ = fmt.Fprintf(b, "Accepts: %v,\n", quoted(spec.Accepts))
+	}
+	if spec.Separator != "" {
+		_, _ = fmt.Fprintf(b, "Separator: %v,\n", strconv.Quote(spec.Separator))
+	}
+	if spec.Map {
+		_, _ = fmt.Fprintf(b, "Map: true,\n")
+	}
+	if spec.Pattern != "" {
+		_, _ = fmt.Fprintf(b, "Pattern: %v,\n", strconv.Quote(spec.Pattern))
+	}
+	if len(spec.RequiredIf) > 0 {
+		_, _ = fmt.Fprintf(b, "RequiredIf: []gen.VarCondition{\n")
+		for _, condition := range spec.RequiredIf {
+			_, _ = fmt.Fprintf(b, "{Name: %v, Value: %v},\n",
+				strconv.Quote(condition.Name), strconv.Quote(condition.Value))
+		}
+		_, _ = fmt.Fprintf(b, "},\n")
+	}
+	if len(spec.Excludes) > 0 {
+		_, _ = fmt.Fprintf(b, "Excludes: %v,\n", quoted(spec.Excludes))
+	}
+	if spec.Group != "" {
+		_, _ = fmt.Fprintf(b, "Group: %v,\n", strconv.Quote(spec.Group))
+	}
+
+	_, _ = fmt.Fprintf(b, "},\n")
+}
+
+// writeLoader writes the schema and the methods Load and LoadWith of s
+func (w *loaderWriter) writeLoader(s loaderStruct) error {
+	specs, err := config.FieldSpecs(s.sType)
+	if err != nil {
+		return err
+	}
+
+	fields := make([]*loaderField, len(specs))
+	for i, spec := range specs {
+		if fields[i], err = newLoaderField(spec); err != nil {
+			return err
+		}
+	}
+
+	prefix := lowerFirst(s.name)
+	schema := prefix + "Schema"
+
+	var specsSrc, body bytes.Buffer
+
+	for i, f := range fields {
+		writeSpec(&specsSrc, &f.spec.VarSpec)
+		w.writeField(&body, f, i, prefix)
+	}
+
+	var holds []string
+	for _, f := range fields {
+		for c, condition := range f.spec.RequiredIf {
+			name := fmt.Sprintf("%vCondition%v", prefix, len(holds))
+
+			expr, err := w.holdsExpr(f, c, fields, name)
+			if err != nil {
+				return err
+			}
+
+			holds = append(holds, fmt.Sprintf("%v, // %v required if %v=%v\n",
+				expr, f.spec.Name, condition.Name, condition.Value))
+		}
+	}
+
+	holdsFunc, holdsArg := "", "nil"
+	if len(holds) > 0 {
+		holdsFunc = "\nholds := func() []bool {\nreturn []bool{\n" + strings.Join(holds, "") + "}\n}\n"
+		holdsArg = "holds"
+	}
+
+	if w.values.Len() > 0 {
+		_, _ = fmt.Fprintf(&w.decls, "// Values of bounds and conditions of %v without a literal\nvar (\n%v)\n\n",
+			s.name, w.values.String())
+		w.values.Reset()
+	}
+
+	_, _ = fmt.Fprintf(&w.decls, `// %[2]v describes the variables of %[1]v
+var %[2]v, %[2]vErr = gen.NewSchema([]gen.VarSpec{
+%[3]v})
+
+// Load fills c with the variables read from vReader, like
+// conf.ConfParser.ParseConf, but without reflection. opts
+// configure the parser, e.g. conf.WithNamespace
+func (c *%[1]v) Load(vReader *envvars.VarReader, opts ...conf.Option) error {
+cp, err := conf.New(vReader, opts...)
+if err != nil {
+return err
+}
+
+return c.LoadWith(cp)
+}
+
+// LoadWith fills c with the variables read by cp, like
+// cp.ParseConf, but without reflection
+func (c *%[1]v) LoadWith(cp *conf.ConfParser) error {
+if %[2]vErr != nil {
+return %[2]vErr
+}
+
+fill := func(i int, rawVal string) error {
+switch i {
+%[4]v}
+
+return nil
+}
+%[5]v
+return cp.ParseGenerated(c, %[2]v, fill, %[6]v)
+}
+
+`, s.name, schema, specsSrc.String(), body.String(), holdsFunc, holdsArg)
+
+	return nil
+}
+
+// writeLoaders writes the source of a file of package pkgName with the
+// methods Load and LoadWith of each struct, which fill it like
+// config.ConfParser.ParseConf but without reflection. Returns the same
+// errors as ParseConf for invalid structs, as well as unsupportedFieldError
+// if some field can't be filled by generated code, e.g. if its type has a
+// registered converter
+func writeLoaders(w io.Writer, pkgName string, structs ...loaderStruct) error {
+	lw := &loaderWriter{
+		imports: map[string]string{
+			reflect.TypeOf(config.ConfParser{}).PkgPath(): "conf",
+			reflect.TypeOf(gen.Schema{}).PkgPath():        "",
+			reflect.TypeOf(envvars.VarReader{}).PkgPath(): "",
+		},
+	}
+
+	for _, s := range structs {
+		if err := lw.writeLoader(s); err != nil {
+			return fmt.Errorf("struct %v: %w", s.name, err)
+		}
+	}
+	paths := make([]string, 0, len(lw.imports))
+	for path := range lw.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+
+	_, _ = fmt.Fprintf(&src, "// Code generated by confgen. DO NOT EDIT.\n\npackage %v\n\nimport (\n", pkgName)
+	for _, path := range paths {
+		_, _ = fmt.Fprintf(&src, "%v %v\n", lw.imports[path], strconv.Quote(path))
+	}
+	_, _ = fmt.Fprintf(&src, ")\n\n%v", lw.decls.String())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(formatted)
+
+	return err
+}
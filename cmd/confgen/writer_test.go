@@ -0,0 +1,124 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"go/parser"
+	"go/token"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type loaderDummy struct {
+	Host  string            `name:"HOST" required:"yes"`
+	Port  *uint16           `name:"PORT" min:"1"`
+	Tags  []string          `name:"TAGS" len:"1..3"`
+	Flags map[string]bool   `name:"FLAGS"`
+	Token string            `name:"TOKEN" required_if:"PORT=443"`
+	Other map[string]string `name:"OTHER" excludes:"TAGS"`
+}
+
+type loaderDummyKey string
+
+type loaderDummyColor string
+
+func (c *loaderDummyColor) UnmarshalConfig(rawVal string) error {
+	*c = loaderDummyColor(rawVal)
+
+	return nil
+}
+
+func TestWriteLoaders(t *testing.T) {
+	var b bytes.Buffer
+
+	err := writeLoaders(&b, "dummy", loaderStruct{name: "loaderDummy", sType: reflect.TypeOf(loaderDummy{})})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	src := b.String()
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", src, 0); err != nil {
+		t.Errorf("Invalid source: %v", err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by confgen. DO NOT EDIT.",
+		"package dummy",
+		"var loaderDummySchema, loaderDummySchemaErr = gen.NewSchema(",
+		"func (c *loaderDummy) Load(vReader *envvars.VarReader, opts ...conf.Option) error {",
+		"func (c *loaderDummy) LoadWith(cp *conf.ConfParser) error {",
+		"gen.ParseOptional(&val, rawVal, gen.ParseUnsigned)",
+		"err = gen.MinViolation(\"1\", *val)",
+		"c.Port != nil && *c.Port == 443",
+		"return cp.ParseGenerated(c, loaderDummySchema, fill, holds)",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("Expecting %q in source:\n%v", expected, src)
+		}
+	}
+}
+
+func TestWriteLoadersInvalidStructs(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		sType    reflect.Type
+		expected any
+	}{
+		{"not a struct", reflect.TypeOf(0), nil},
+		{"missing name", reflect.TypeOf(struct {
+			Host string `required:"yes"`
+		}{}), new(*config.MissingTagKeyError)},
+		{"unsupported type", reflect.TypeOf(struct {
+			Ch chan int `name:"CH"`
+		}{}), new(*config.UnsupportedTypeError)},
+		{"named map key", reflect.TypeOf(struct {
+			M map[loaderDummyKey]int `name:"M"`
+		}{}), new(*unsupportedFieldError)},
+		{"optional elements", reflect.TypeOf(struct {
+			S []*int `name:"S"`
+		}{}), new(*unsupportedFieldError)},
+		{"unmarshaler", reflect.TypeOf(struct {
+			C loaderDummyColor `name:"C"`
+		}{}), new(*unsupportedFieldError)},
+		{"condition of url", reflect.TypeOf(struct {
+			U *url.URL `name:"U"`
+			V string   `name:"V" required_if:"U=http://host"`
+		}{}), new(*unsupportedFieldError)},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			err := writeLoaders(&bytes.Buffer{}, "dummy", loaderStruct{name: "Dummy", sType: pair.sType})
+
+			switch {
+			case err == nil:
+				t.Errorf("Expecting error")
+			case pair.expected == nil && !errors.Is(err, config.InvalidValuePointedError):
+				t.Errorf("Expecting error InvalidValuePointedError, got %v", err)
+			case pair.expected != nil && !errors.As(err, pair.expected):
+				t.Errorf("Expecting error of type %T, got %v", pair.expected, err)
+			}
+		})
+	}
+}
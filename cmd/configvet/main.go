@@ -0,0 +1,31 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command configvet reports invalid config structs, see package configtags.
+// It's meant to be run by go vet, in order to analyze the whole module:
+//
+//	go build -o bin/configvet ./cmd/configvet
+//	go vet -vettool=$(pwd)/bin/configvet ./...
+package main
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config/configtags"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(configtags.Analyzer)
+}
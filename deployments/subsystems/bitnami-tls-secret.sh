@@ -5,6 +5,10 @@
 
 # Warning: depends on helm-deployment.sh
 
+# The copied secret can be mounted as is in a pod. Its files (tls.crt,
+# tls.key and ca.crt) are read by defining the path of each one in the
+# *_SECRET_FILE variables, e.g. REDIS_TLS_CERT_SECRET_FILE=/tls/tls.crt
+
 check_variables \
   EXTERNAL_NAMESPACES \
   EXTERNAL_SECRET \
@@ -1,13 +1,16 @@
 module github.com/franciscosbf/micro-dwarf
 
-go 1.20
+go 1.22.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/google/uuid v1.3.0
 	github.com/jackc/pgconn v1.13.0
 	github.com/jackc/pgx/v4 v4.17.2
 	github.com/redis/go-redis/v9 v9.0.2
 	github.com/twpayne/go-geom v1.5.0
+	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -21,5 +24,7 @@ require (
 	github.com/jackc/pgtype v1.12.0 // indirect
 	github.com/jackc/puddle v1.3.0 // indirect
 	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/text v0.3.7 // indirect
 )
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"github.com/franciscosbf/micro-dwarf/internal/clis"
 	"github.com/franciscosbf/micro-dwarf/internal/clis/postgres/config"
+	conf "github.com/franciscosbf/micro-dwarf/internal/config"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 	"github.com/franciscosbf/micro-dwarf/internal/errorw"
 	"github.com/franciscosbf/micro-dwarf/internal/secure"
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"sync/atomic"
 )
 
 const (
@@ -36,51 +38,35 @@ const (
 // dsnConn returns a dsn containing only connection elements
 func dsnConn(varsConf *config.PostgresConfig) (dsn string) {
 	dsn = fmt.Sprintf(
-		"user=%v password=%v host=%v dbname=%v",
-		varsConf.User, varsConf.Password, varsConf.Host, varsConf.Dbname)
-
-	if port := varsConf.Port; port != 0 {
-		dsn = fmt.Sprintf("%v port=%v", dsn, port)
-	}
-
-	if sslMode := varsConf.SslMode; sslMode != "" {
-		dsn = fmt.Sprintf("%v sslmode=%v", dsn, sslMode)
-	}
+		"user=%v password=%v host=%v port=%v dbname=%v sslmode=%v",
+		varsConf.User, varsConf.Password, varsConf.Host,
+		varsConf.Port, varsConf.Dbname, varsConf.SslMode)
 
 	return
 }
 
 // populatePgxDefs sets up all pgxConf parameters if present in varsConf
 func populatePgxDefs(varsConf *config.PostgresConfig, pgxConf *pgxpool.Config) (err error) {
-	utils.SetAny(varsConf.PoolMaxCons, &pgxConf.MaxConns)
-	utils.SetAny(varsConf.PoolMinCons, &pgxConf.MinConns)
-	utils.SetAny(varsConf.PoolMaxConnLifetime, &pgxConf.MaxConnLifetime)
-	utils.SetAny(varsConf.PoolMaxConnIdleTime, &pgxConf.MaxConnIdleTime)
-	utils.SetAny(varsConf.PoolHealthCheckPeriod, &pgxConf.HealthCheckPeriod)
-	utils.SetAny(varsConf.PoolMaxConnLifetimeJitter, &pgxConf.MaxConnLifetimeJitter)
-
-	if varsConf.UseTls {
+	utils.SetOptional(varsConf.PoolMaxCons, &pgxConf.MaxConns)
+	utils.SetOptional(varsConf.PoolMinCons, &pgxConf.MinConns)
+	utils.SetOptional(varsConf.PoolMaxConnLifetimeJitter, &pgxConf.MaxConnLifetimeJitter)
+
+	// Variables with a default value
+	pgxConf.MaxConnLifetime = varsConf.PoolMaxConnLifetime
+	pgxConf.MaxConnIdleTime = varsConf.PoolMaxConnIdleTime
+	pgxConf.HealthCheckPeriod = varsConf.PoolHealthCheckPeriod
+
+	if tlsConf := varsConf.Tls; tlsConf.UseTls {
 		pgxConf.ConnConfig.TLSConfig, err = secure.GenClientTls(
-			varsConf.TlsHostName, varsConf.TlsCert,
-			varsConf.TlsKey, varsConf.TlsCA)
+			tlsConf.HostName, tlsConf.Cert,
+			tlsConf.Key, tlsConf.CA)
 	}
 
 	return
 }
 
-// New creates a new pool and checks db connection
-func New(vReader *envvars.VarReader) (*pgxpool.Pool, error) {
-	if vReader == nil {
-		return nil, errorw.WrapErrorf(
-			clis.ErrorCodeMissingReader, nil, "Postgres variables reader is nil")
-	}
-
-	varsConf, err := config.New(vReader)
-	if err != nil {
-		return nil, errorw.WrapErrorf(
-			clis.ErrorCodeVarReader, err, "Couldn't build Postgres variables config")
-	}
-
+// connect creates a new pool from the variables config and checks db connection
+func connect(varsConf *config.PostgresConfig) (*pgxpool.Pool, error) {
 	dsn := dsnConn(varsConf)
 
 	pgxConf, err := pgxpool.ParseConfig(dsn)
@@ -102,9 +88,88 @@ func New(vReader *envvars.VarReader) (*pgxpool.Pool, error) {
 
 	// Checks if connection is ok
 	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+
 		return nil, errorw.WrapErrorf(
 			ErrorCodeQueryCheckFail, err, "Couldn't perform query check in Postgres database")
 	}
 
-	return pool, err
+	return pool, nil
+}
+
+// New creates a new pool and checks db connection. If the variables
+// config is invalid, the returned error wraps the VariablesError of the
+// config parser, which lists every invalid variable at once
+func New(vReader *envvars.VarReader) (*pgxpool.Pool, error) {
+	return NewWithNamespace(vReader, "")
+}
+
+// NewWithNamespace is like New, but variables are prefixed by namespace,
+// e.g. USERS_POSTGRES_HOST, which allows to connect to several databases
+func NewWithNamespace(vReader *envvars.VarReader, namespace string) (*pgxpool.Pool, error) {
+	if vReader == nil {
+		return nil, errorw.WrapErrorf(
+			clis.ErrorCodeMissingReader, nil, "Postgres variables reader is nil")
+	}
+
+	varsConf, err := config.NewWithNamespace(vReader, namespace)
+	if err != nil {
+		return nil, errorw.WrapErrorf(
+			clis.ErrorCodeVarReader, err, "Couldn't build Postgres variables config")
+	}
+
+	return connect(varsConf)
+}
+
+// ReloadablePool holds a pool that is replaced by a new
+// one whenever the pool configuration changes on reload,
+// since pgxpool doesn't allow to change it on the fly
+type ReloadablePool struct {
+	pool atomic.Pointer[pgxpool.Pool]
+}
+
+// Pool returns the current pool. It must be fetched on each use, since a
+// replaced pool is closed as soon as all its acquired connections are released
+func (rp *ReloadablePool) Pool() *pgxpool.Pool {
+	return rp.pool.Load()
+}
+
+// Close closes the current pool
+func (rp *ReloadablePool) Close() {
+	rp.pool.Load().Close()
+}
+
+// apply replaces the current pool by a new one with the reloaded
+// config. If the new one can't be created, keeps the current
+func (rp *ReloadablePool) apply(varsConf *config.PostgresConfig, _ []*conf.FieldChange) error {
+	pool, err := connect(varsConf)
+	if err != nil {
+		return err
+	}
+
+	// Blocks until all acquired connections are released
+	go rp.pool.Swap(pool).Close()
+
+	return nil
+}
+
+// NewReloadable creates a pool from the current config of watcher, which
+// is replaced on each reload. Only the pool configuration can be reloaded,
+// i.e. connection related variables are ignored. Errors are the same as New
+func NewReloadable(watcher *conf.Watcher[config.PostgresConfig]) (*ReloadablePool, error) {
+	if watcher == nil {
+		return nil, errorw.WrapErrorf(
+			clis.ErrorCodeMissingReader, nil, "Postgres config watcher is nil")
+	}
+
+	pool, err := connect(watcher.Current())
+	if err != nil {
+		return nil, err
+	}
+
+	rp := &ReloadablePool{}
+	rp.pool.Store(pool)
+	watcher.Subscribe(rp.apply)
+
+	return rp, nil
 }
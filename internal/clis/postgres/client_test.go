@@ -110,6 +110,31 @@ func TestInvalidConnection(t *testing.T) {
 				checkErrorCode(t, cli, err, clis.ErrorCodeVarReader, "ErrorCodeVarReader")
 			},
 		},
+		{
+			name: "TestNamespacedPgxDsnFailure",
+			test: func(t *testing.T) {
+				t.Setenv("USERS_POSTGRES_USER_SECRET", "user")
+				t.Setenv("USERS_POSTGRES_PASSWORD_SECRET", "password")
+				t.Setenv("USERS_POSTGRES_HOST", "localhost")
+				t.Setenv("USERS_POSTGRES_DBNAME", "\"databa     ")
+
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := NewWithNamespace(reader, "USERS")
+				checkErrorCode(t, cli, err, ErrorCodeClientDsnFail, "ErrorCodeClientDsnFail")
+			},
+		},
+		{
+			name: "TestInvalidNamespace",
+			test: func(t *testing.T) {
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := NewWithNamespace(reader, "USERS-")
+				checkErrorCode(t, cli, err, clis.ErrorCodeVarReader, "ErrorCodeVarReader")
+			},
+		},
 		{
 			name: "TestPgxDsnFailure",
 			test: func(t *testing.T) {
@@ -138,6 +163,9 @@ func TestInvalidConnection(t *testing.T) {
 				setVar("POSTGRES_DBNAME", "database")
 
 				setVar("POSTGRES_TLS", "true")
+				setVar("POSTGRES_TLS_CERT_SECRET", "cert")
+				setVar("POSTGRES_TLS_KEY_SECRET", "key")
+				setVar("POSTGRES_TLS_CA_SECRET", "ca")
 
 				envProvider := providers.NewEnvVariables()
 				reader := envvars.New(envProvider)
@@ -146,6 +174,25 @@ func TestInvalidConnection(t *testing.T) {
 				checkErrorCode(t, cli, err, clis.ErrorCodeClientConfigFail, "ErrorCodeClientConfigFail")
 			},
 		},
+		{
+			name: "TestMissingTlsVars",
+			test: func(t *testing.T) {
+				defer unsetVars()
+
+				setVar("POSTGRES_USER_SECRET", "user")
+				setVar("POSTGRES_PASSWORD_SECRET", "password")
+				setVar("POSTGRES_HOST", "localhost")
+				setVar("POSTGRES_DBNAME", "database")
+
+				setVar("POSTGRES_TLS", "true")
+
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := New(reader)
+				checkErrorCode(t, cli, err, clis.ErrorCodeVarReader, "ErrorCodeVarReader")
+			},
+		},
 		{
 			name: "TestPgxConnFailure",
 			test: func(t *testing.T) {
@@ -17,8 +17,10 @@ limitations under the License.
 package config
 
 import (
+	conf "github.com/franciscosbf/micro-dwarf/internal/config"
 	"github.com/franciscosbf/micro-dwarf/internal/conftemplate"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/secure"
 	"time"
 )
 
@@ -28,35 +30,37 @@ import (
 type PostgresConfig struct {
 	// Connection related
 
-	User     string `name:"POSTGRES_USER_SECRET" required:"yes"`
-	Password string `name:"POSTGRES_PASSWORD_SECRET" required:"yes"`
-	Host     string `name:"POSTGRES_HOST" required:"yes"`
-	Port     uint16 `name:"POSTGRES_PORT"`
-	Dbname   string `name:"POSTGRES_DBNAME" required:"yes"`
-	SslMode  string `name:"POSTGRES_SSL_MODE" accepts:"disable,allow,prefer,require,verify-ca,verify-full"`
+	User     string `name:"POSTGRES_USER_SECRET" required:"yes" reload:"no"`                                                             // Database user
+	Password string `name:"POSTGRES_PASSWORD_SECRET" required:"yes" reload:"no"`                                                         // Database user password
+	Host     string `name:"POSTGRES_HOST" required:"yes" reload:"no"`                                                                    // Database server host
+	Port     uint16 `name:"POSTGRES_PORT" min:"1" default:"5432" reload:"no"`                                                            // Database server port
+	Dbname   string `name:"POSTGRES_DBNAME" required:"yes" reload:"no"`                                                                  // Database name
+	SslMode  string `name:"POSTGRES_SSL_MODE" accepts:"disable,allow,prefer,require,verify-ca,verify-full" default:"prefer" reload:"no"` // SSL negotiation mode, see libpq sslmode
 
 	// Secure connection
 
-	UseTls      bool   `name:"POSTGRES_TLS"`
-	TlsHostName string `name:"POSTGRES_TLS_HOSTNAME_SECRET"`
-	TlsCert     string `name:"POSTGRES_TLS_CERT_SECRET"`
-	TlsKey      string `name:"POSTGRES_TLS_KEY_SECRET"`
-	TlsCA       string `name:"POSTGRES_TLS_CA_SECRET"`
+	Tls secure.TlsConfig `prefix:"POSTGRES_" reload:"no"` // Client certificate authentication
 
 	// Pool configuration
 
-	PoolMaxCons               int32         `name:"POSTGRES_POOL_MAX_CONS"`
-	PoolMinCons               int32         `name:"POSTGRES_POOL_MIN_CONS"`
-	PoolMaxConnLifetime       time.Duration `name:"POSTGRES_POOL_MAX_CONN_LIFETIME"`
-	PoolMaxConnIdleTime       time.Duration `name:"POSTGRES_POOL_MAX_CONN_IDLE_TIME"`
-	PoolHealthCheckPeriod     time.Duration `name:"POSTGRES_POOL_HEALTH_CHECK_PERIOD"`
-	PoolMaxConnLifetimeJitter time.Duration `name:"POSTGRES_POOL_MAX_CONN_LIFETIME_JITTER"`
+	PoolMaxCons               *int32         `name:"POSTGRES_POOL_MAX_CONS" min:"1" max:"500"`       // Maximum pool size (defaults to the greater of 4 or the number of CPUs)
+	PoolMinCons               *int32         `name:"POSTGRES_POOL_MIN_CONS" min:"0" max:"500"`       // Minimum pool size
+	PoolMaxConnLifetime       time.Duration  `name:"POSTGRES_POOL_MAX_CONN_LIFETIME" default:"1h"`   // Duration since creation after which a connection is closed
+	PoolMaxConnIdleTime       time.Duration  `name:"POSTGRES_POOL_MAX_CONN_IDLE_TIME" default:"30m"` // Duration after which an idle connection is closed
+	PoolHealthCheckPeriod     time.Duration  `name:"POSTGRES_POOL_HEALTH_CHECK_PERIOD" default:"1m"` // Interval between health checks of idle connections
+	PoolMaxConnLifetimeJitter *time.Duration `name:"POSTGRES_POOL_MAX_CONN_LIFETIME_JITTER"`         // Random duration added to the lifetime of each connection
 }
 
 // New returns a new postgres config
 func New(vReader *envvars.VarReader) (template *PostgresConfig, err error) {
+	return NewWithNamespace(vReader, "")
+}
+
+// NewWithNamespace returns a new postgres config, whose variables
+// are prefixed by namespace (if not empty), e.g. USERS_POSTGRES_TLS
+func NewWithNamespace(vReader *envvars.VarReader, namespace string) (template *PostgresConfig, err error) {
 	template = &PostgresConfig{}
-	err = conftemplate.Read(vReader, template)
+	err = conftemplate.Read(vReader, template, conf.WithNamespace(namespace))
 
 	return
 }
@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"github.com/franciscosbf/micro-dwarf/internal/clis"
 	"github.com/franciscosbf/micro-dwarf/internal/clis/redis/config"
+	conf "github.com/franciscosbf/micro-dwarf/internal/config"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 	"github.com/franciscosbf/micro-dwarf/internal/errorw"
 	"github.com/franciscosbf/micro-dwarf/internal/secure"
 	"github.com/redis/go-redis/v9"
+	"sync/atomic"
+	"time"
 )
 
 // Error codes
@@ -33,6 +36,20 @@ const (
 	ErrorCodeNodeConnFail errorw.ErrorCode = iota
 )
 
+// disabledIfZero returns the value pointed by optional, where an explicit
+// zero is replaced by -1, which go-redis interprets as disabled. If optional
+// is nil, returns zero so that go-redis applies its default
+func disabledIfZero[T int | time.Duration](optional *T) T {
+	switch {
+	case optional == nil:
+		return 0
+	case *optional == 0:
+		return -1
+	default:
+		return *optional
+	}
+}
+
 // createClusterConf initializes the cluster options, returning it
 func createClusterConf(varsConf *config.RedisConfig) (opts *redis.ClusterOptions, err error) {
 	opts = &redis.ClusterOptions{
@@ -44,16 +61,19 @@ func createClusterConf(varsConf *config.RedisConfig) (opts *redis.ClusterOptions
 		ReadOnly:              varsConf.ReadOnlySlaves,
 		PoolFIFO:              varsConf.PoolFifo,
 		ContextTimeoutEnabled: varsConf.ContextTimeoutEnabled,
-		MaxRedirects:          varsConf.MaxRedirects,
-		MaxRetries:            varsConf.MaxRetries,
 		PoolSize:              varsConf.PoolSize,
 		MinIdleConns:          varsConf.MinIdleConnections,
-		MinRetryBackoff:       varsConf.MinRetryBackOff,
-		MaxRetryBackoff:       varsConf.MaxRetryBackOff,
 		DialTimeout:           varsConf.DialTimeout,
 		ReadTimeout:           varsConf.ReadTimout,
 		WriteTimeout:          varsConf.WriteTimout,
 		PoolTimeout:           varsConf.PoolTimeout,
+
+		// Optional fields where an explicit zero disables the feature
+
+		MaxRedirects:    disabledIfZero(varsConf.MaxRedirects),
+		MaxRetries:      disabledIfZero(varsConf.MaxRetries),
+		MinRetryBackoff: disabledIfZero(varsConf.MinRetryBackOff),
+		MaxRetryBackoff: disabledIfZero(varsConf.MaxRetryBackOff),
 	}
 
 	// Add node addresses
@@ -75,10 +95,10 @@ func createClusterConf(varsConf *config.RedisConfig) (opts *redis.ClusterOptions
 		opts.RouteRandomly = true
 	}
 
-	if varsConf.UseTls {
+	if tlsConf := varsConf.Tls; tlsConf.UseTls {
 		opts.TLSConfig, err = secure.GenClientTls(
-			varsConf.TlsHostName, varsConf.TlsCert,
-			varsConf.TlsKey, varsConf.TlsCA)
+			tlsConf.HostName, tlsConf.Cert,
+			tlsConf.Key, tlsConf.CA)
 	}
 
 	return
@@ -90,33 +110,109 @@ func pingNode(ctx context.Context, client *redis.Client) error {
 	return client.Ping(ctx).Err()
 }
 
-// New creates a new cluster cli and checks connection with all shards
+// connect creates a new cluster cli from the variables
+// config and checks connection with all shards
+func connect(varsConf *config.RedisConfig) (*redis.ClusterClient, error) {
+	cConf, err := createClusterConf(varsConf)
+	if err != nil {
+		return nil, errorw.WrapErrorf(
+			clis.ErrorCodeClientConfigFail, err, "Invalid Redis config options")
+	}
+
+	cli := redis.NewClusterClient(cConf)
+
+	// Iterates over all nodes to perform a heath-check
+	ctx := context.Background()
+	if err := cli.ForEachShard(ctx, pingNode); err != nil {
+		_ = cli.Close()
+
+		return nil, errorw.WrapErrorf(
+			ErrorCodeNodeConnFail, err, "Failed Redis connection check in a node")
+	}
+
+	return cli, nil
+}
+
+// New creates a new cluster cli and checks connection with all shards. If
+// the variables config is invalid, the returned error wraps
+// the VariablesError of the config parser, which lists every invalid
+// variable at once
 func New(vReader *envvars.VarReader) (*redis.ClusterClient, error) {
+	return NewWithNamespace(vReader, "")
+}
+
+// NewWithNamespace is like New, but variables are prefixed by namespace,
+// e.g. USERS_REDIS_ADDRS, which allows to connect to several clusters
+func NewWithNamespace(vReader *envvars.VarReader, namespace string) (*redis.ClusterClient, error) {
 	if vReader == nil {
 		return nil, errorw.WrapErrorf(
 			clis.ErrorCodeMissingReader, nil, "Redis variables reader is nil")
 	}
 
-	varsConf, err := config.New(vReader)
+	varsConf, err := config.NewWithNamespace(vReader, namespace)
 	if err != nil {
 		return nil, errorw.WrapErrorf(
 			clis.ErrorCodeVarReader, err, "Couldn't build Redis variables config")
 	}
 
-	cConf, err := createClusterConf(varsConf)
+	return connect(varsConf)
+}
+
+// replacedClientGracePeriod is the time given to a replaced
+// cli to finish running commands before being closed
+const replacedClientGracePeriod = 30 * time.Second
+
+// ReloadableClient holds a cluster cli that is replaced by a new one
+// whenever the connection pool configuration changes on reload, since
+// go-redis doesn't allow to change it on the fly
+type ReloadableClient struct {
+	cli atomic.Pointer[redis.ClusterClient]
+}
+
+// Client returns the current cli. It must be fetched on each use, since a
+// replaced cli is closed after replacedClientGracePeriod
+func (rc *ReloadableClient) Client() *redis.ClusterClient {
+	return rc.cli.Load()
+}
+
+// Close closes the current cli
+func (rc *ReloadableClient) Close() error {
+	return rc.cli.Load().Close()
+}
+
+// apply replaces the current cli by a new one with the
+// reloaded config. If the new one can't be created, keeps the current
+func (rc *ReloadableClient) apply(varsConf *config.RedisConfig, _ []*conf.FieldChange) error {
+	cli, err := connect(varsConf)
 	if err != nil {
-		return nil, errorw.WrapErrorf(
-			clis.ErrorCodeClientConfigFail, err, "Invalid Redis config options")
+		return err
 	}
 
-	cli := redis.NewClusterClient(cConf)
+	replaced := rc.cli.Swap(cli)
+	time.AfterFunc(replacedClientGracePeriod, func() {
+		_ = replaced.Close()
+	})
 
-	// Iterates over all nodes to perform a heath-check
-	ctx := context.Background()
-	if err := cli.ForEachShard(ctx, pingNode); err != nil {
+	return nil
+}
+
+// NewReloadable creates a cluster cli from the current config of watcher,
+// which is replaced on each reload. Addresses and credentials can't be
+// reloaded, i.e. their changes are ignored. Errors are the same as New
+func NewReloadable(watcher *conf.Watcher[config.RedisConfig]) (*ReloadableClient, error) {
+	if watcher == nil {
 		return nil, errorw.WrapErrorf(
-			ErrorCodeNodeConnFail, err, "Failed Redis connection check in a node")
+			clis.ErrorCodeMissingReader, nil, "Redis config watcher is nil")
 	}
 
-	return cli, nil
+	cli, err := connect(watcher.Current())
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &ReloadableClient{}
+	rc.cli.Store(cli)
+	watcher.Subscribe(rc.apply)
+
+	return rc, nil
 }
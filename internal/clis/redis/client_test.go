@@ -18,9 +18,11 @@ package redis
 
 import (
 	"github.com/franciscosbf/micro-dwarf/internal/clis"
+	"github.com/franciscosbf/micro-dwarf/internal/clis/redis/config"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars/providers"
 	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
 	"github.com/redis/go-redis/v9"
 	"os"
 	"strings"
@@ -113,6 +115,9 @@ func TestInvalidConnection(t *testing.T) {
 
 				setVar("REDIS_ADDRS", "127.255.254.123:1234")
 				setVar("REDIS_TLS", "true")
+				setVar("REDIS_TLS_CERT_SECRET", "cert")
+				setVar("REDIS_TLS_KEY_SECRET", "key")
+				setVar("REDIS_TLS_CA_SECRET", "ca")
 
 				envProvider := providers.NewEnvVariables()
 				reader := envvars.New(envProvider)
@@ -121,6 +126,21 @@ func TestInvalidConnection(t *testing.T) {
 				checkErrorCode(t, cli, err, clis.ErrorCodeClientConfigFail, "ErrorCodeClientConfigFail")
 			},
 		},
+		{
+			name: "TestMissingTlsVars",
+			test: func(t *testing.T) {
+				defer unsetVars()
+
+				setVar("REDIS_ADDRS", "127.255.254.123:1234")
+				setVar("REDIS_TLS", "true")
+
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := New(reader)
+				checkErrorCode(t, cli, err, clis.ErrorCodeVarReader, "ErrorCodeVarReader")
+			},
+		},
 		{
 			name: "TestClusterConnFailure",
 			test: func(t *testing.T) {
@@ -135,9 +155,60 @@ func TestInvalidConnection(t *testing.T) {
 				checkErrorCode(t, cli, err, ErrorCodeNodeConnFail, "ErrorCodeNodeConnFail")
 			},
 		},
+		{
+			name: "TestNamespacedInvalidTls",
+			test: func(t *testing.T) {
+				t.Setenv("USERS_REDIS_ADDRS", "127.255.254.123:1234")
+				t.Setenv("USERS_REDIS_TLS", "true")
+				t.Setenv("USERS_REDIS_TLS_CERT_SECRET", "cert")
+				t.Setenv("USERS_REDIS_TLS_KEY_SECRET", "key")
+				t.Setenv("USERS_REDIS_TLS_CA_SECRET", "ca")
+
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := NewWithNamespace(reader, "USERS")
+				checkErrorCode(t, cli, err, clis.ErrorCodeClientConfigFail, "ErrorCodeClientConfigFail")
+			},
+		},
+		{
+			name: "TestInvalidNamespace",
+			test: func(t *testing.T) {
+				envProvider := providers.NewEnvVariables()
+				reader := envvars.New(envProvider)
+
+				cli, err := NewWithNamespace(reader, "USERS-")
+				checkErrorCode(t, cli, err, clis.ErrorCodeVarReader, "ErrorCodeVarReader")
+			},
+		},
 	}
 
 	for _, pair := range testBattery {
 		t.Run(pair.name, pair.test)
 	}
 }
+
+func TestClusterConfZeroValues(t *testing.T) {
+	addrs, _ := utils.ParseAddrs("127.255.254.123:1234")
+	zero := 0
+
+	opts, err := createClusterConf(&config.RedisConfig{
+		Addrs:      addrs,
+		MaxRetries: &zero,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Explicit zero disables retries, while
+	// timeouts keep the default of go-redis
+	if opts.MaxRetries != -1 {
+		t.Errorf("Expecting disabled retries, got %v", opts.MaxRetries)
+	}
+	if opts.MaxRedirects != 0 {
+		t.Errorf("Expecting default redirects, got %v", opts.MaxRedirects)
+	}
+	if opts.ReadTimeout != 0 || opts.WriteTimeout != 0 {
+		t.Errorf("Expecting default timeouts, got %v and %v", opts.ReadTimeout, opts.WriteTimeout)
+	}
+}
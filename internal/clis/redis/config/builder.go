@@ -17,8 +17,10 @@ limitations under the License.
 package config
 
 import (
+	conf "github.com/franciscosbf/micro-dwarf/internal/config"
 	"github.com/franciscosbf/micro-dwarf/internal/conftemplate"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/secure"
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
 	"time"
 )
@@ -27,40 +29,42 @@ import (
 type RedisConfig struct {
 	// Connection related
 
-	Addrs    *utils.Addrs `name:"REDIS_ADDRS" required:"yes"` // see utils.ParseAddrs
-	Username string       `name:"REDIS_USERNAME_SECRET"`
-	Password string       `name:"REDIS_PASSWORD_SECRET"`
+	Addrs    *utils.Addrs `name:"REDIS_ADDRS" required:"yes" reload:"no"` // Cluster nodes addresses, see utils.ParseAddrs
+	Username string       `name:"REDIS_USERNAME_SECRET" reload:"no"`      // ACL username
+	Password string       `name:"REDIS_PASSWORD_SECRET" reload:"no"`      // ACL user password
 
 	// Secure connection
 
-	UseTls      bool   `name:"REDIS_TLS"`
-	TlsHostName string `name:"REDIS_TLS_HOSTNAME_SECRET"`
-	TlsCert     string `name:"REDIS_TLS_CERT_SECRET"`
-	TlsKey      string `name:"REDIS_TLS_KEY_SECRET"`
-	TlsCA       string `name:"REDIS_TLS_CA_SECRET"`
+	Tls secure.TlsConfig `prefix:"REDIS_" reload:"no"` // Client certificate authentication
 
 	// Connection and pool configurations
 
-	RouteMode             string        `name:"REDIS_ROUTE_MODE" accepts:"latency,randomly"`
-	ReadOnlySlaves        bool          `name:"REDIS_READ_ONY_SLAVES"`
-	PoolFifo              bool          `name:"REDIS_POOL_FIFO"`
-	ContextTimeoutEnabled bool          `name:"REDIS_CONTEXT_TIMEOUT_ENABLED"`
-	MaxRedirects          int           `name:"REDIS_MAX_REDIRECTS"`
-	MaxRetries            int           `name:"REDIS_MAX_RETRIES"`
-	PoolSize              int           `name:"REDIS_POOL_SIZE"`
-	MinIdleConnections    int           `name:"REDIS_MIN_IDLE_CONNECTIONS"`
-	MinRetryBackOff       time.Duration `name:"REDIS_MIN_RETRY_BACKOFF"`
-	MaxRetryBackOff       time.Duration `name:"REDIS_MAX_RETRY_BACKOFF"`
-	DialTimeout           time.Duration `name:"REDIS_DIAL_TIMEOUT"`
-	ReadTimout            time.Duration `name:"REDIS_READ_TIMEOUT"`
-	WriteTimout           time.Duration `name:"REDIS_WRITE_TIMEOUT"`
-	PoolTimeout           time.Duration `name:"REDIS_POOL_TIMEOUT"`
+	RouteMode             string         `name:"REDIS_ROUTE_MODE" accepts:"latency,randomly"`            // Routes read-only commands to the closest or a random node
+	ReadOnlySlaves        bool           `name:"REDIS_READ_ONLY_SLAVES" aliases:"REDIS_READ_ONY_SLAVES"` // Enables read-only commands on slave nodes
+	PoolFifo              bool           `name:"REDIS_POOL_FIFO"`                                        // Uses the pool as a FIFO queue instead of LIFO
+	ContextTimeoutEnabled bool           `name:"REDIS_CONTEXT_TIMEOUT_ENABLED"`                          // Respects context timeouts and deadlines
+	MaxRedirects          *int           `name:"REDIS_MAX_REDIRECTS" min:"0"`                            // Maximum number of MOVED/ASK redirects (0 disables them)
+	MaxRetries            *int           `name:"REDIS_MAX_RETRIES" min:"0"`                              // Maximum number of retries before giving up (0 disables them)
+	PoolSize              int            `name:"REDIS_POOL_SIZE" min:"1"`                                // Maximum number of connections per node
+	MinIdleConnections    int            `name:"REDIS_MIN_IDLE_CONNECTIONS"`                             // Minimum number of idle connections per node
+	MinRetryBackOff       *time.Duration `name:"REDIS_MIN_RETRY_BACKOFF" min:"0"`                        // Minimum backoff between retries (0 disables it)
+	MaxRetryBackOff       *time.Duration `name:"REDIS_MAX_RETRY_BACKOFF" min:"0"`                        // Maximum backoff between retries (0 disables it)
+	DialTimeout           time.Duration  `name:"REDIS_DIAL_TIMEOUT" min:"100ms"`                         // Timeout for establishing new connections
+	ReadTimout            time.Duration  `name:"REDIS_READ_TIMEOUT"`                                     // Timeout for socket reads (0 applies the default of 3s)
+	WriteTimout           time.Duration  `name:"REDIS_WRITE_TIMEOUT"`                                    // Timeout for socket writes (0 applies the default of 3s)
+	PoolTimeout           time.Duration  `name:"REDIS_POOL_TIMEOUT"`                                     // Time to wait for a connection if all are busy
 }
 
 // New returns a new redis config
 func New(vReader *envvars.VarReader) (template *RedisConfig, err error) {
+	return NewWithNamespace(vReader, "")
+}
+
+// NewWithNamespace returns a new redis config, whose variables
+// are prefixed by namespace (if not empty), e.g. USERS_REDIS_TLS
+func NewWithNamespace(vReader *envvars.VarReader, namespace string) (template *RedisConfig, err error) {
 	template = &RedisConfig{}
-	err = conftemplate.Read(vReader, template)
+	err = conftemplate.Read(vReader, template, conf.WithNamespace(namespace))
 
 	return
 }
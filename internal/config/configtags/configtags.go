@@ -0,0 +1,558 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configtags defines an analyzer that checks the tags of config
+// structs, i.e. the ones passed to conftemplate.Read, ConfParser.ParseConf
+// and NewWatcher, or the ones with loaders generated by confgen, reporting
+// the errors that the config parser would only return at runtime
+package configtags
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gotypes"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const doc = `check tags of config structs
+
+The configtags analyzer evaluates each struct passed to conftemplate.Read,
+ConfParser.ParseConf or config.NewWatcher, as well as the ones named by a
+confgen go:generate directive, like the config parser does, reporting missing
+or invalid tags (e.g. name, accepts values that don't match the field
+type), unsupported field types, private and anonymous fields. Values of
+types parsed by unmarshalers or by converters registered in the analyzed
+packages (see config.RegisterConverter) aren't validated, since that
+requires running the program.
+
+Variable names are also compared against the ones of structs parsed by
+imported packages, reporting the ones defined by another struct. This
+is skipped if the namespace of the parser isn't a constant. Structs of
+confgen are compared when their generated Load or LoadWith method is called.`
+
+// Analyzer reports invalid config structs
+var Analyzer = &analysis.Analyzer{
+	Name:      "configtags",
+	Doc:       doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(convertersFact), new(variablesFact)},
+}
+
+const (
+	configPath       = "github.com/franciscosbf/micro-dwarf/internal/config"
+	conftemplatePath = "github.com/franciscosbf/micro-dwarf/internal/conftemplate"
+)
+
+// convertersFact contains the types whose
+// converters are registered by a package
+type convertersFact struct {
+	Types []string
+}
+
+func (*convertersFact) AFact() {}
+
+func (f *convertersFact) String() string {
+	return "converters(" + strings.Join(f.Types, ", ") + ")"
+}
+
+// variablesFact maps each variable read by a package
+// to the config struct that defines it
+type variablesFact struct {
+	Owners map[string]string
+}
+
+func (*variablesFact) AFact() {}
+
+func (f *variablesFact) String() string {
+	names := make([]string, 0, len(f.Owners))
+	for name := range f.Owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return "variables(" + strings.Join(names, ", ") + ")"
+}
+
+// opaque replaces types parsed by unmarshalers or by registered
+// converters, whose values can't be validated statically
+type opaque string
+
+func (*opaque) UnmarshalConfig(string) error { return nil }
+
+// builtinTypes maps the qualified name of each type with
+// a builtin converter of the config parser to its type
+var builtinTypes = func() map[string]reflect.Type {
+	m := make(map[string]reflect.Type)
+	for _, t := range gen.LoaderTypes() {
+		m[gotypes.TypeKey(t)] = t
+	}
+
+	return m
+}()
+
+// namespace is the namespace of a parser, where
+// known tells if it could be determined statically
+type namespace struct {
+	name  string
+	known bool
+}
+
+// checked is the result of evaluating a config struct
+type checked struct {
+	names []string
+	err   error
+}
+
+// checker evaluates the config structs of a package
+type checker struct {
+	pass       *analysis.Pass
+	registered map[string]bool
+	parsers    map[types.Object]namespace
+	structs    map[string]*checked
+	owners     map[string]string
+	local      map[string]string
+}
+
+// calleeOf returns the function called by call if it's
+// declared in the package pkgPath. Otherwise, returns nil
+func (c *checker) calleeOf(call *ast.CallExpr, pkgPath string) *types.Func {
+	fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return nil
+	}
+
+	return fn
+}
+
+// typeArgOf returns the type argument of the call of a generic function
+func (c *checker) typeArgOf(call *ast.CallExpr) types.Type {
+	fun := ast.Unparen(call.Fun)
+
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	}
+
+	instance, ok := c.pass.TypesInfo.Instances[id]
+	if !ok || instance.TypeArgs.Len() == 0 {
+		return nil
+	}
+
+	return instance.TypeArgs.At(0)
+}
+
+// resolve maps builtin types to themselves, while the ones
+// parsed by unmarshalers or registered converters are opaque
+func (c *checker) resolve(t types.Type, _ string) (reflect.Type, bool, error) {
+	key := types.TypeString(t, nil)
+
+	if rType, ok := builtinTypes[key]; ok {
+		return rType, true, nil
+	}
+
+	if !c.registered[key] && !gotypes.IsUnmarshaler(t) {
+		return nil, false, nil
+	}
+
+	if _, ok := t.(*types.Pointer); ok {
+		return reflect.TypeOf((*opaque)(nil)), true, nil
+	}
+
+	return reflect.TypeOf(opaque("")), true, nil
+}
+
+// namespaceOf returns the namespace set by opts, see config.WithNamespace.
+// If some option isn't a call of a config option, it's unknown
+func (c *checker) namespaceOf(opts []ast.Expr, spread bool) namespace {
+	ns := namespace{known: !spread}
+
+	for _, opt := range opts {
+		call, ok := ast.Unparen(opt).(*ast.CallExpr)
+		if !ok {
+			return namespace{}
+		}
+
+		fn := c.calleeOf(call, configPath)
+		switch {
+		case fn == nil:
+			return namespace{}
+		case fn.Name() != "WithNamespace":
+			continue
+		}
+
+		value := c.pass.TypesInfo.Types[call.Args[0]].Value
+		if value == nil || value.Kind() != constant.String {
+			return namespace{}
+		}
+
+		ns.name = utils.PolishString(constant.StringVal(value))
+	}
+
+	return ns
+}
+
+// recordParser saves the namespace of the parser assigned to
+// id by call, if it's a call of config.New. Parsers assigned
+// more than once with distinct namespaces are unknown
+func (c *checker) recordParser(id *ast.Ident, call *ast.CallExpr) {
+	fn := c.calleeOf(call, configPath)
+	if fn == nil || fn.Name() != "New" || len(call.Args) == 0 {
+		return
+	}
+
+	obj := c.pass.TypesInfo.ObjectOf(id)
+	if obj == nil {
+		return
+	}
+
+	ns := c.namespaceOf(call.Args[1:], call.Ellipsis.IsValid())
+	if prev, ok := c.parsers[obj]; ok && prev != ns {
+		ns = namespace{}
+	}
+
+	c.parsers[obj] = ns
+}
+
+// parserNamespace returns the namespace of the parser x
+func (c *checker) parserNamespace(x ast.Expr) namespace {
+	id, ok := ast.Unparen(x).(*ast.Ident)
+	if !ok {
+		return namespace{}
+	}
+
+	return c.parsers[c.pass.TypesInfo.ObjectOf(id)]
+}
+
+// check evaluates the struct of type sType, returning its variables
+func (c *checker) check(sType types.Type, srt *types.Struct) *checked {
+	key := types.TypeString(sType, nil)
+	if result, ok := c.structs[key]; ok {
+		return result
+	}
+
+	result := &checked{}
+	c.structs[key] = result
+
+	rType, err := gotypes.StructOf(srt, "", c.resolve)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	refs, err := config.Reference(reflect.New(rType).Interface(), nil)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	for _, ref := range refs {
+		result.names = append(result.names, ref.Name)
+	}
+
+	return result
+}
+
+// checkArg reports the errors of the config struct arg. Its variables
+// are compared against the ones of other structs if ns is known
+func (c *checker) checkArg(arg ast.Expr, ns namespace) {
+	argType := c.pass.TypesInfo.TypeOf(arg)
+	if argType == nil {
+		return
+	}
+
+	ptr, ok := argType.Underlying().(*types.Pointer)
+	if !ok {
+		if _, isStruct := argType.Underlying().(*types.Struct); isStruct {
+			c.pass.Reportf(arg.Pos(), "config struct %v: %v",
+				types.TypeString(argType, nil), config.InvalidPointerError)
+		}
+
+		return
+	}
+
+	c.checkStruct(arg.Pos(), ptr.Elem(), ns)
+}
+
+// checkStruct reports at pos the errors of the config struct of type sType,
+// if it's a struct. Its variables are compared against the ones of other
+// structs if ns is known
+func (c *checker) checkStruct(pos token.Pos, sType types.Type, ns namespace) {
+	srt, ok := sType.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+
+	key := types.TypeString(sType, nil)
+
+	result := c.check(sType, srt)
+	if result.err != nil {
+		c.pass.Reportf(pos, "config struct %v: %v", key, result.err)
+		return
+	}
+
+	c.compareVariables(pos, key, result, ns)
+}
+
+// compareVariables reports at pos the variables of the config struct
+// key that are defined by other structs, if the namespace ns is known
+func (c *checker) compareVariables(pos token.Pos, key string, result *checked, ns namespace) {
+	if !ns.known {
+		return
+	}
+
+	for _, name := range result.names {
+		if ns.name != "" {
+			name = ns.name + "_" + name
+		}
+
+		if owner, ok := c.owners[name]; ok && owner != key {
+			c.pass.Reportf(pos,
+				"config struct %v: variable %v is also defined by %v", key, name, owner)
+			continue
+		}
+
+		c.local[name] = key
+	}
+}
+
+// confgenTypes returns the types named by the confgen
+// go:generate directives of the package, e.g.
+//
+//	//go:generate go run ../cmd/confgen -type PostgresConfig,RedisConfig
+func confgenTypes(pass *analysis.Pass) []*types.TypeName {
+	var typeNames []*types.TypeName
+
+	for _, file := range pass.Files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				directive, ok := strings.CutPrefix(comment.Text, "//go:generate ")
+				if !ok {
+					continue
+				}
+
+				for _, name := range confgenTypeNames(strings.Fields(directive)) {
+					if tn, ok := pass.Pkg.Scope().Lookup(name).(*types.TypeName); ok {
+						typeNames = append(typeNames, tn)
+					}
+				}
+			}
+		}
+	}
+
+	return typeNames
+}
+
+// confgenTypeNames returns the value of the flag type
+// if args is a command that runs confgen
+func confgenTypeNames(args []string) []string {
+	runsConfgen := false
+
+	var names string
+	for i, arg := range args {
+		switch {
+		case arg == "confgen" || strings.HasSuffix(arg, "/confgen"):
+			runsConfgen = true
+		case (arg == "-type" || arg == "--type") && i+1 < len(args):
+			names = args[i+1]
+		case strings.HasPrefix(arg, "-type="):
+			names = strings.TrimPrefix(arg, "-type=")
+		case strings.HasPrefix(arg, "--type="):
+			names = strings.TrimPrefix(arg, "--type=")
+		}
+	}
+
+	if !runsConfgen || names == "" {
+		return nil
+	}
+
+	return strings.Split(names, ",")
+}
+
+// generatedLoad returns the struct whose Load or LoadWith method,
+// generated by confgen, is called by call, along with the namespace
+// of the parser. Otherwise, returns nil
+func (c *checker) generatedLoad(
+	call *ast.CallExpr,
+	generated map[types.Object]bool,
+) (types.Type, *types.Struct, namespace) {
+	fn, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	if !ok || len(call.Args) == 0 {
+		return nil, nil, namespace{}
+	}
+
+	var ns namespace
+	switch fn.Name() {
+	case "Load":
+		ns = c.namespaceOf(call.Args[1:], call.Ellipsis.IsValid())
+	case "LoadWith":
+		ns = c.parserNamespace(call.Args[0])
+	default:
+		return nil, nil, namespace{}
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return nil, nil, namespace{}
+	}
+
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+
+	named, ok := recvType.(*types.Named)
+	if !ok || !generated[named.Obj()] {
+		return nil, nil, namespace{}
+	}
+
+	srt, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil, namespace{}
+	}
+
+	return named, srt, ns
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	c := &checker{
+		pass:       pass,
+		registered: make(map[string]bool),
+		parsers:    make(map[types.Object]namespace),
+		structs:    make(map[string]*checked),
+		owners:     make(map[string]string),
+		local:      make(map[string]string),
+	}
+
+	for _, fact := range pass.AllPackageFacts() {
+		switch f := fact.Fact.(type) {
+		case *convertersFact:
+			for _, t := range f.Types {
+				c.registered[t] = true
+			}
+		case *variablesFact:
+			for name, owner := range f.Owners {
+				c.owners[name] = owner
+			}
+		}
+	}
+
+	// Converters and parsers are collected before
+	// checking structs, since they can be used earlier
+	var registered []string
+	var calls []*ast.CallExpr
+
+	filter := []ast.Node{(*ast.CallExpr)(nil), (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}
+	insp.Preorder(filter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if fn := c.calleeOf(n, configPath); fn != nil && fn.Name() == "RegisterConverter" {
+				if t := c.typeArgOf(n); t != nil {
+					key := types.TypeString(t, nil)
+					c.registered[key] = true
+					registered = append(registered, key)
+				}
+			}
+
+			calls = append(calls, n)
+		case *ast.AssignStmt:
+			if len(n.Rhs) == 1 && len(n.Lhs) > 0 {
+				if call, ok := ast.Unparen(n.Rhs[0]).(*ast.CallExpr); ok {
+					if id, ok := n.Lhs[0].(*ast.Ident); ok {
+						c.recordParser(id, call)
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Values) == 1 && len(n.Names) > 0 {
+				if call, ok := ast.Unparen(n.Values[0]).(*ast.CallExpr); ok {
+					c.recordParser(n.Names[0], call)
+				}
+			}
+		}
+	})
+
+	// Structs of confgen are reported at their declaration,
+	// given that the loader is generated from it
+	generated := make(map[types.Object]bool)
+	for _, tn := range confgenTypes(pass) {
+		generated[tn] = true
+		c.checkStruct(tn.Pos(), tn.Type(), namespace{})
+	}
+
+	for _, call := range calls {
+		if fn := c.calleeOf(call, conftemplatePath); fn != nil && fn.Name() == "Read" && len(call.Args) >= 2 {
+			c.checkArg(call.Args[1], c.namespaceOf(call.Args[2:], call.Ellipsis.IsValid()))
+			continue
+		}
+
+		if sType, srt, ns := c.generatedLoad(call, generated); sType != nil {
+			if result := c.check(sType, srt); result.err == nil {
+				c.compareVariables(call.Pos(), types.TypeString(sType, nil), result, ns)
+			}
+
+			continue
+		}
+
+		fn := c.calleeOf(call, configPath)
+		if fn == nil || len(call.Args) != 1 {
+			continue
+		}
+
+		switch fn.Name() {
+		case "ParseConf":
+			if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+				c.checkArg(call.Args[0], c.parserNamespace(sel.X))
+			}
+		case "NewWatcher":
+			if t := c.typeArgOf(call); t != nil {
+				c.checkStruct(call.Pos(), t, c.parserNamespace(call.Args[0]))
+			}
+		}
+	}
+
+	if len(registered) > 0 {
+		pass.ExportPackageFact(&convertersFact{Types: registered})
+	}
+	if len(c.local) > 0 {
+		pass.ExportPackageFact(&variablesFact{Owners: c.local})
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,91 @@
+package app // want package:"variables\\(APP_HOST, COLOR, COLOR_NAME, ENDPOINT, HOST, LABELS, LEVEL, SINCE, TIMEOUT\\)"
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/internal/conftemplate"
+	"github.com/franciscosbf/micro-dwarf/settings"
+	"net/url"
+	"os"
+	"time"
+)
+
+type Level string
+
+func (l *Level) UnmarshalText(text []byte) error { return nil }
+
+type Valid struct {
+	Endpoint *url.URL          `name:"ENDPOINT"`
+	Level    Level             `name:"LEVEL" accepts:"debug,info"`
+	Color    settings.Color    `name:"COLOR_NAME"`
+	Labels   map[string]string `name:"LABELS" sep:";"`
+	Since    *time.Time        `name:"SINCE"`
+}
+
+type MissingName struct {
+	Host string `required:"yes"`
+}
+
+type Unsupported struct {
+	Ratio complex64 `name:"RATIO"`
+}
+
+type InvalidAccepts struct {
+	Port int `name:"PORT" accepts:"80,http"`
+}
+
+type InvalidDefault struct {
+	Timeout time.Duration `name:"TIMEOUT" default:"soon"`
+}
+
+type Private struct {
+	host string `name:"HOST"`
+}
+
+type creds struct {
+	User string `name:"USER"`
+}
+
+type PrivateEmbedded struct {
+	X int `name:"X"`
+	creds
+}
+
+type Anonymous struct {
+	Level `name:"LEVEL"`
+}
+
+type Duplicated struct {
+	Host string `name:"HOST"`
+}
+
+type Options []config.Option
+
+func Check(cp *config.ConfParser, opts []config.Option) {
+	_ = conftemplate.Read(nil, &Valid{})
+	_ = conftemplate.Read(nil, &MissingName{})     // want `config struct .*MissingName: missing tag name in struct field Host`
+	_ = conftemplate.Read(nil, &Unsupported{})     // want `struct field Ratio contains unsupported type complex64`
+	_ = conftemplate.Read(nil, &InvalidAccepts{})  // want `config struct .*InvalidAccepts: .*accepted value http`
+	_ = conftemplate.Read(nil, &InvalidDefault{})  // want `config struct .*InvalidDefault: .*invalid value "soon" in tag key default`
+	_ = conftemplate.Read(nil, &Private{})         // want `field host is private`
+	_ = conftemplate.Read(nil, &PrivateEmbedded{}) // want `field creds is private`
+	_ = conftemplate.Read(nil, &Anonymous{})       // want `config struct .*Anonymous: .*Level`
+	_ = conftemplate.Read(nil, Valid{})            // want `expecting pointer`
+
+	// Variables of other packages
+	_ = conftemplate.Read(nil, &Duplicated{}) // want `variable HOST is also defined by github.com/franciscosbf/micro-dwarf/settings.Server`
+	_ = conftemplate.Read(nil, &settings.Server{})
+	_ = conftemplate.Read(nil, &Duplicated{}, config.WithNamespace("APP"), config.WithStrict())
+	_ = conftemplate.Read(nil, &Duplicated{}, opts...)
+
+	parser, _ := config.New(nil, config.WithNamespace(" "))
+	_ = parser.ParseConf(&Duplicated{})  // want `variable HOST is also defined`
+	_ = parser.ParseConf(&MissingName{}) // want `missing tag name`
+
+	namespace := os.Getenv("NAMESPACE")
+	other, _ := config.New(nil, config.WithNamespace(namespace))
+	_ = other.ParseConf(&Duplicated{})
+	_ = cp.ParseConf(&Duplicated{})
+
+	var conf any = &MissingName{}
+	_ = cp.ParseConf(conf)
+}
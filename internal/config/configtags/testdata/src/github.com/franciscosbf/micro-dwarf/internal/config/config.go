@@ -0,0 +1,22 @@
+// Package config is a stub of the config parser
+package config
+
+type StructPtr = any
+
+type Option func(cp *ConfParser)
+
+type ConfParser struct{}
+
+func New(varReader any, opts ...Option) (*ConfParser, error) { return &ConfParser{}, nil }
+
+func WithNamespace(namespace string) Option { return nil }
+
+func WithStrict() Option { return nil }
+
+func (cp *ConfParser) ParseConf(conf StructPtr) error { return nil }
+
+func RegisterConverter[T any](parse func(rawVal string) (T, error)) error { return nil }
+
+type Watcher[T any] struct{}
+
+func NewWatcher[T any](parser *ConfParser) (*Watcher[T], error) { return &Watcher[T]{}, nil }
@@ -0,0 +1,6 @@
+// Package conftemplate is a stub of the config reader
+package conftemplate
+
+import "github.com/franciscosbf/micro-dwarf/internal/config"
+
+func Read(vReader any, conf config.StructPtr, opts ...config.Option) error { return nil }
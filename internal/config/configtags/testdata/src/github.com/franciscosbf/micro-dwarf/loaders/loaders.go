@@ -0,0 +1,61 @@
+package loaders // want package:"variables\\(APP_DB_HOST, CACHE_ADDR, DB_HOST, LOADERS_CACHE_ADDR, LOADERS_DB_HOST\\)"
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/settings"
+)
+
+//go:generate go run github.com/franciscosbf/micro-dwarf/cmd/confgen -type DB,Broken
+//go:generate go run ../cmd/confgen -type=PrivateEmbedded -output private_loader.go
+
+type DB struct {
+	Host string `name:"DB_HOST"`
+}
+
+func (c *DB) Load(vReader any, opts ...config.Option) error { return nil }
+
+func (c *DB) LoadWith(cp *config.ConfParser) error { return nil }
+
+type Broken struct { // want `config struct .*Broken: missing tag name in struct field Port`
+	Port int
+}
+
+func (c *Broken) Load(vReader any, opts ...config.Option) error { return nil }
+
+type creds struct {
+	User string `name:"USER"`
+}
+
+type PrivateEmbedded struct { // want `config struct .*PrivateEmbedded: field creds is private`
+	creds
+}
+
+type Cache struct {
+	Addr string `name:"CACHE_ADDR"`
+}
+
+type Duplicated struct {
+	Host string `name:"HOST"`
+}
+
+type Unsupported struct {
+	Ratio complex64 `name:"RATIO"`
+}
+
+func Check(vReader any) {
+	db := &DB{}
+	_ = db.Load(vReader)
+	_ = db.Load(vReader, config.WithNamespace("APP"))
+	_ = (&Broken{}).Load(vReader)
+
+	_ = settings.Read
+
+	parser, _ := config.New(vReader)
+	_, _ = config.NewWatcher[Cache](parser)
+	_, _ = config.NewWatcher[Duplicated](parser)  // want `variable HOST is also defined by github.com/franciscosbf/micro-dwarf/settings.Server`
+	_, _ = config.NewWatcher[Unsupported](parser) // want `struct field Ratio contains unsupported type complex64`
+
+	namespaced, _ := config.New(vReader, config.WithNamespace("LOADERS"))
+	_, _ = config.NewWatcher[Cache](namespaced)
+	_ = db.LoadWith(namespaced)
+}
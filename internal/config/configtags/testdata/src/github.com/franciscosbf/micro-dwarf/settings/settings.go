@@ -0,0 +1,26 @@
+package settings
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/internal/conftemplate"
+	"time"
+)
+
+type Color int
+
+type Server struct {
+	Host    string        `name:"HOST" required:"yes"`
+	Timeout time.Duration `name:"TIMEOUT" default:"5s"`
+	Color   Color         `name:"COLOR" accepts:"red,blue"`
+}
+
+func init() {
+	_ = config.RegisterConverter(func(rawVal string) (Color, error) { return 0, nil })
+}
+
+func Read() (*Server, error) {
+	s := &Server{}
+	err := conftemplate.Read(nil, s)
+
+	return s, err
+}
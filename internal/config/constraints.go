@@ -0,0 +1,319 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// valueConstraints contains the constraints that a converted value must
+// respect. Bounds are values of the field type, or of the elements type
+// in case of collections. The maximum length is -1 when unbounded
+type valueConstraints struct {
+	min, max       *reflect.Value
+	rawMin, rawMax string
+	pattern        *regexp.Regexp
+	hasLen         bool
+	minLen, maxLen int
+}
+
+// indirectType returns the type pointed by t if it's an optional,
+// since constraints are applied to the value pointed by the field
+func indirectType(t reflect.Type) reflect.Type {
+	if isOptional(t) {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// compareMethodName is the method of non-numeric orderable
+// types, with the signature func(T) int, e.g. time.Time.Compare
+const compareMethodName = "Compare"
+
+// hasCompareMethod tells if t has the method Compare(t) int
+func hasCompareMethod(t reflect.Type) bool {
+	method, ok := t.MethodByName(compareMethodName)
+	if !ok {
+		return false
+	}
+
+	// The receiver is the first argument
+	mType := method.Type
+
+	return mType.NumIn() == 2 && mType.In(1) == t &&
+		mType.NumOut() == 1 && mType.Out(0).Kind() == reflect.Int
+}
+
+// isOrderable tells if values of t can be compared with min and max,
+// i.e. if they're numeric or if they have the method Compare(T) int
+func isOrderable(t reflect.Type) bool {
+	t = indirectType(t)
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return hasCompareMethod(t)
+	}
+}
+
+// hasLength tells if len can be applied to values of t
+func hasLength(t reflect.Type) bool {
+	return indirectType(t).Kind() == reflect.String || isCollection(t)
+}
+
+// compareValues returns -1 if a < b, 0 if a == b and 1 if a > b.
+// Assumes that both are orderable values of the same type. Optionals
+// are compared by the values they point to, which can't be nil
+func compareValues(a, b reflect.Value) int {
+	a, b = reflect.Indirect(a), reflect.Indirect(b)
+
+	var diff float64
+
+	switch {
+	case hasCompareMethod(a.Type()):
+		in := []reflect.Value{b}
+		diff = float64(a.MethodByName(compareMethodName).Call(in)[0].Int())
+	case a.CanInt():
+		x, y := a.Int(), b.Int()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	case a.CanUint():
+		x, y := a.Uint(), b.Uint()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	default:
+		diff = a.Float() - b.Float()
+	}
+
+	switch {
+	case diff < 0:
+		return -1
+	case diff > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// valueLength returns the number of characters of a
+// string or the number of elements of a collection
+func valueLength(val reflect.Value) int {
+	if val = reflect.Indirect(val); val.Kind() == reflect.String {
+		return utf8.RuneCountInString(val.String())
+	}
+
+	return val.Len()
+}
+
+// elementsOf returns each element (or entry value)
+// of a collection, or val itself if it isn't one
+func elementsOf(val reflect.Value) []reflect.Value {
+	if !isCollection(val.Type()) {
+		return []reflect.Value{val}
+	}
+
+	var elems []reflect.Value
+
+	if val.Kind() == reflect.Map {
+		iter := val.MapRange()
+		for iter.Next() {
+			elems = append(elems, iter.Value())
+		}
+
+		return elems
+	}
+
+	for i := 0; i < val.Len(); i++ {
+		elems = append(elems, val.Index(i))
+	}
+
+	return elems
+}
+
+// check verifies if val respects all constraints, where rawElems
+// are the raw values of each element matched against the pattern.
+// Returns ConstraintViolationError on the first violation
+func (c *valueConstraints) check(val reflect.Value, rawElems []string) error {
+	if c.hasLen {
+		length := valueLength(val)
+		if length < c.minLen || (c.maxLen != -1 && length > c.maxLen) {
+			return &ConstraintViolationError{
+				keyName:  lenTagKey,
+				bound:    c.rawLen(),
+				rawValue: fmt.Sprintf("with length %v", length),
+			}
+		}
+	}
+
+	for _, elem := range elementsOf(val) {
+		if c.min != nil && compareValues(elem, *c.min) < 0 {
+			return &ConstraintViolationError{
+				keyName:  minTagKey,
+				bound:    c.rawMin,
+				rawValue: fmt.Sprint(reflect.Indirect(elem).Interface()),
+			}
+		}
+
+		if c.max != nil && compareValues(elem, *c.max) > 0 {
+			return &ConstraintViolationError{
+				keyName:  maxTagKey,
+				bound:    c.rawMax,
+				rawValue: fmt.Sprint(reflect.Indirect(elem).Interface()),
+			}
+		}
+	}
+
+	if c.pattern != nil {
+		for _, raw := range rawElems {
+			if !c.pattern.MatchString(raw) {
+				return &ConstraintViolationError{
+					keyName:  patternTagKey,
+					bound:    c.pattern.String(),
+					rawValue: raw,
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// rawLen returns the length bounds in the tag format
+func (c *valueConstraints) rawLen() string {
+	switch {
+	case c.minLen == c.maxLen:
+		return strconv.Itoa(c.minLen)
+	case c.maxLen == -1:
+		return fmt.Sprintf("%v%v", c.minLen, lenRangeSeparator)
+	default:
+		return fmt.Sprintf("%v%v%v", c.minLen, lenRangeSeparator, c.maxLen)
+	}
+}
+
+// parseBound converts the raw bound with the elements converter of
+// v. Returns InvalidTagKeyValueFmtError if the elements type isn't
+// orderable or if the bound doesn't match it
+func parseBound(v *variableInfo, fieldT reflect.Type, key, rawBound string) (*reflect.Value, error) {
+	elemT := elemType(fieldT)
+
+	invalidBound := func(reason string) (*reflect.Value, error) {
+		return nil, &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   key,
+			rawValue:  rawBound,
+			reason:    reason,
+		}
+	}
+
+	if !isOrderable(elemT) {
+		return invalidBound(fmt.Sprintf("type %v isn't orderable", elemT))
+	}
+
+	bound := reflect.New(elemT).Elem()
+	if err := v.elemSetValue(&bound, rawBound); err != nil {
+		return invalidBound(fmt.Sprintf("doesn't match type %v", elemT))
+	}
+
+	return &bound, nil
+}
+
+// parseFieldConstraints parses the tag keys min, max, pattern and
+// len, checking if they can be applied to the field type. Bounds
+// are converted by the elements converter, which must be already
+// assigned to v. If there isn't any constraint, v is left untouched
+func parseFieldConstraints(v *variableInfo, field *reflect.StructField) error {
+	c := &valueConstraints{}
+	hasAny := false
+
+	for _, key := range []string{minTagKey, maxTagKey} {
+		rawBound, ok, err := parseTagKeyBound(field, key)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		bound, err := parseBound(v, field.Type, key, rawBound)
+		if err != nil {
+			return err
+		}
+
+		if key == minTagKey {
+			c.min, c.rawMin = bound, rawBound
+		} else {
+			c.max, c.rawMax = bound, rawBound
+		}
+
+		hasAny = true
+	}
+
+	if c.min != nil && c.max != nil && compareValues(*c.min, *c.max) > 0 {
+		return &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   minTagKey,
+			rawValue:  c.rawMin,
+			reason:    fmt.Sprintf("greater than %v %v", maxTagKey, c.rawMax),
+		}
+	}
+
+	pattern, err := parseTagKeyPattern(field)
+	if err != nil {
+		return err
+	}
+	if pattern != nil {
+		c.pattern = pattern
+		hasAny = true
+	}
+
+	minLen, maxLen, ok, err := parseTagKeyLen(field)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if !hasLength(field.Type) {
+			return &InvalidTagKeyValueFmtError{
+				fieldName: v.fieldPath,
+				keyName:   lenTagKey,
+				rawValue:  field.Tag.Get(lenTagKey),
+				reason:    "length is only allowed in strings, slices and maps",
+			}
+		}
+
+		c.hasLen, c.minLen, c.maxLen = true, minLen, maxLen
+		hasAny = true
+	}
+
+	if hasAny {
+		v.constraints = c
+	}
+
+	return nil
+}
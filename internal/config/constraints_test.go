@@ -0,0 +1,259 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidTagKeyLenParsing(t *testing.T) {
+	checkReturn := func(t *testing.T, minLen, maxLen int, srtPtr any) {
+		sf := reflect.TypeOf(srtPtr).Elem().Field(0)
+
+		gotMin, gotMax, ok, err := parseTagKeyLen(&sf)
+		if err != nil {
+			t.Errorf("Expecting nil err, got %v", err)
+		}
+
+		if !ok {
+			t.Errorf("Expecting len to be present in tag %v", sf.Tag)
+		}
+
+		if gotMin != minLen || gotMax != maxLen {
+			t.Errorf("Expecting bounds %v and %v, got %v and %v", minLen, maxLen, gotMin, gotMax)
+		}
+	}
+
+	testBattery := []struct {
+		name string
+		test func(t *testing.T)
+	}{
+		{
+			name: "TestExact",
+			test: func(t *testing.T) {
+				checkReturn(t, 3, 3, &struct {
+					S string `len:"3"`
+				}{})
+			},
+		},
+		{
+			name: "TestRange",
+			test: func(t *testing.T) {
+				checkReturn(t, 1, 8, &struct {
+					S string `len:"1 .. 8"`
+				}{})
+			},
+		},
+		{
+			name: "TestWithoutMaximum",
+			test: func(t *testing.T) {
+				checkReturn(t, 8, -1, &struct {
+					S string `len:"8.."`
+				}{})
+			},
+		},
+		{
+			name: "TestWithoutMinimum",
+			test: func(t *testing.T) {
+				checkReturn(t, 0, 8, &struct {
+					S string `len:"..8"`
+				}{})
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, pair.test)
+	}
+}
+
+func TestInvalidTagKeyLenParsing(t *testing.T) {
+	fields := []any{
+		&struct {
+			S string `len:""`
+		}{},
+		&struct {
+			S string `len:".."`
+		}{},
+		&struct {
+			S string `len:"-1"`
+		}{},
+		&struct {
+			S string `len:"a..2"`
+		}{},
+		&struct {
+			S string `len:"3..2"`
+		}{},
+	}
+
+	for _, srtPtr := range fields {
+		sf := reflect.TypeOf(srtPtr).Elem().Field(0)
+
+		if _, _, _, err := parseTagKeyLen(&sf); err == nil {
+			t.Errorf("Expecting error in tag %v", sf.Tag)
+		} else if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+			t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+		}
+	}
+}
+
+func TestInvalidTagKeyPatternParsing(t *testing.T) {
+	sf := reflect.TypeOf(&struct {
+		S string `pattern:"[a-z"`
+	}{}).Elem().Field(0)
+
+	if _, err := parseTagKeyPattern(&sf); err == nil {
+		t.Error("Expecting getting an error")
+	} else if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+		t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+	}
+}
+
+func TestInvalidFieldConstraints(t *testing.T) {
+	testBattery := []struct {
+		name   string
+		srtPtr any
+	}{
+		{
+			name: "TestBoundOfInvalidType",
+			srtPtr: &struct {
+				I int `name:"hi" min:"one"`
+			}{},
+		},
+		{
+			name: "TestBoundInNonNumeric",
+			srtPtr: &struct {
+				S string `name:"hi" max:"a"`
+			}{},
+		},
+		{
+			name: "TestMinGreaterThanMax",
+			srtPtr: &struct {
+				D time.Duration `name:"hi" min:"1m" max:"1s"`
+			}{},
+		},
+		{
+			name: "TestLenInNumeric",
+			srtPtr: &struct {
+				I int `name:"hi" len:"2"`
+			}{},
+		},
+		{
+			name: "TestDefaultViolation",
+			srtPtr: &struct {
+				I int `name:"hi" max:"10" default:"11"`
+			}{},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			st := reflect.ValueOf(pair.srtPtr).Elem()
+			vs, err := parseFields(st.Type())
+			if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+				t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+			}
+
+			if vs != nil {
+				t.Errorf("Expecting nil slice, got: %v", vs)
+			}
+		})
+	}
+}
+
+func TestValidConstraintsParseConf(t *testing.T) {
+	type Dummy struct {
+		I  int32         `name:"CONSTRAINT_1" min:"1" max:"500"`
+		D  time.Duration `name:"CONSTRAINT_2" min:"100ms"`
+		S  string        `name:"CONSTRAINT_3" pattern:"^[a-z]+$" len:"2..5"`
+		Is []uint16      `name:"CONSTRAINT_4" max:"10" len:"..3"`
+	}
+
+	setVars(map[string]string{
+		"CONSTRAINT_1": "500",
+		"CONSTRAINT_2": "1s",
+		"CONSTRAINT_3": "abc",
+		"CONSTRAINT_4": "1,10",
+	})
+	defer unsetVars("CONSTRAINT_1", "CONSTRAINT_2", "CONSTRAINT_3", "CONSTRAINT_4")
+
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if d.I != 500 || d.D != time.Second || d.S != "abc" || len(d.Is) != 2 {
+		t.Errorf("Unexpected assigned values: %+v", d)
+	}
+}
+
+func TestInvalidConstraintsParseConf(t *testing.T) {
+	type Dummy struct {
+		I  int32         `name:"CONSTRAINT_1" min:"1" max:"500"`
+		D  time.Duration `name:"CONSTRAINT_2" min:"100ms"`
+		S  string        `name:"CONSTRAINT_3" pattern:"^[a-z]+$"`
+		L  string        `name:"CONSTRAINT_4" len:"2..5"`
+		Is []uint16      `name:"CONSTRAINT_5" max:"10"`
+	}
+
+	setVars(map[string]string{
+		"CONSTRAINT_1": "0",
+		"CONSTRAINT_2": "10ms",
+		"CONSTRAINT_3": "ABC",
+		"CONSTRAINT_4": "abcdef",
+		"CONSTRAINT_5": "1,11",
+	})
+	defer unsetVars("CONSTRAINT_1", "CONSTRAINT_2", "CONSTRAINT_3", "CONSTRAINT_4", "CONSTRAINT_5")
+
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	d := &Dummy{}
+	vErr, ok := cp.ParseConf(d).(*VariablesError)
+	if !ok {
+		t.Error("Expecting error of type VariablesError")
+		return
+	}
+
+	errs := vErr.Errors()
+	if len(errs) != reflect.TypeOf(d).Elem().NumField() {
+		t.Errorf("Expecting one error per field, got %v", vErr)
+		return
+	}
+
+	for _, err := range errs {
+		wErr, ok := err.(*errorw.Wrapper)
+		if !ok || wErr.Code() != ErrorCodeConstraintViolation {
+			t.Errorf("Expecting error code ErrorCodeConstraintViolation, got %v", err)
+			continue
+		}
+
+		if _, ok := wErr.Unwrap().(*ConstraintViolationError); !ok {
+			t.Errorf("Expecting origin ConstraintViolationError, got %v", wErr.Unwrap())
+		}
+	}
+
+	if d.I != 0 || d.Is != nil {
+		t.Errorf("Expecting fields to remain unchanged, got %+v", d)
+	}
+}
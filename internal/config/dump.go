@@ -0,0 +1,173 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DumpFormat represents the output format of Dump
+type DumpFormat int
+
+// Dump formats
+const (
+	// DumpText renders a line per variable in the format NAME=value
+	DumpText DumpFormat = iota
+	// DumpJSON renders an object with a key per variable
+	DumpJSON
+)
+
+// RedactedValue replaces the value of secret variables
+const RedactedValue = "******"
+
+// formatMarshaler returns the text of ptr if it implements
+// encoding.TextMarshaler or fmt.Stringer (by this order)
+func formatMarshaler(ptr reflect.Value) (string, bool) {
+	switch v := ptr.Interface().(type) {
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return string(text), true
+		}
+	case fmt.Stringer:
+		return v.String(), true
+	}
+
+	return "", false
+}
+
+// formatScalar returns the textual representation of a non-collection
+// value. encoding.TextMarshaler has precedence over fmt.Stringer, which
+// may be implemented by the value or by a pointer to it (e.g. url.URL).
+// Nil pointers are formatted as an empty string
+func formatScalar(val reflect.Value) string {
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return ""
+		}
+
+		if text, ok := formatMarshaler(val); ok {
+			return text
+		}
+
+		// Optionals are formatted by the value they point to
+		val = val.Elem()
+	}
+
+	ptr := reflect.New(val.Type())
+	ptr.Elem().Set(val)
+
+	if text, ok := formatMarshaler(ptr); ok {
+		return text
+	}
+
+	return fmt.Sprint(val.Interface())
+}
+
+// formatValue returns the textual representation of a field value, where
+// collection elements are joined with sep. Map entries are sorted by key
+func formatValue(val reflect.Value, sep string) string {
+	if !isCollection(val.Type()) {
+		return formatScalar(val)
+	}
+
+	var elems []string
+
+	if val.Kind() == reflect.Map {
+		iter := val.MapRange()
+		for iter.Next() {
+			elems = append(elems, fmt.Sprintf("%v%v%v",
+				iter.Key(), keyValueSeparator, formatScalar(iter.Value())))
+		}
+		sort.Strings(elems)
+	} else {
+		for i := 0; i < val.Len(); i++ {
+			elems = append(elems, formatScalar(val.Index(i)))
+		}
+	}
+
+	return strings.Join(elems, sep)
+}
+
+// dumpValue returns the textual representation of the current field
+// value in srtVal, or RedactedValue if it's a non-empty secret
+func dumpValue(v *variableInfo, srtVal *reflect.Value) string {
+	formatted := formatValue(v.fieldOf(srtVal), v.collectionSeparator())
+	if v.secret && formatted != "" {
+		return RedactedValue
+	}
+
+	return formatted
+}
+
+// Dump renders the current values of a config struct, usually after being
+// filled by ConfParser.ParseConf, by the order of its fields. Secret values
+// are replaced by RedactedValue, unless they're empty. Returns the same struct
+// related errors as ConfParser.ParseConf or InvalidDumpFormatError. Variable
+// names are rendered without namespace; see ConfParser.Dump
+func Dump(from StructPtr, format DumpFormat) (string, error) {
+	return dump(from, format, func(name string) string { return name })
+}
+
+// Dump behaves like the package level Dump, but renders variable names with
+// the parser's namespace, as they're read from the source
+func (cp *ConfParser) Dump(from StructPtr, format DumpFormat) (string, error) {
+	return dump(from, format, cp.varName)
+}
+
+// dump renders the struct values, naming each variable with varName
+func dump(from StructPtr, format DumpFormat, varName func(string) string) (string, error) {
+	srtVal, err := extractStrVal(from)
+	if err != nil {
+		return "", err
+	}
+
+	variables, err := cachedFields(srtVal.Type())
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	switch format {
+	case DumpText:
+		for _, v := range variables {
+			fmt.Fprintf(&b, "%v=%v\n", varName(v.name), dumpValue(v, srtVal))
+		}
+	case DumpJSON:
+		// Object is built by hand to keep the fields order
+		b.WriteString("{")
+		for i, v := range variables {
+			if i > 0 {
+				b.WriteString(",")
+			}
+
+			name, _ := json.Marshal(varName(v.name))
+			value, _ := json.Marshal(dumpValue(v, srtVal))
+			fmt.Fprintf(&b, "%s:%s", name, value)
+		}
+		b.WriteString("}")
+	default:
+		return "", InvalidDumpFormatError
+	}
+
+	return b.String(), nil
+}
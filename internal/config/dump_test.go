@@ -0,0 +1,192 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dumpDummy struct {
+	Host     string            `name:"DUMP_HOST"`
+	Password string            `name:"DUMP_PASSWORD_SECRET"`
+	Token    string            `name:"DUMP_TOKEN" secret:"yes"`
+	Public   string            `name:"DUMP_PUBLIC_SECRET" secret:"no"`
+	Empty    string            `name:"DUMP_EMPTY_SECRET"`
+	Timeout  time.Duration     `name:"DUMP_TIMEOUT"`
+	Addrs    *utils.Addrs      `name:"DUMP_ADDRS"`
+	Origins  []string          `name:"DUMP_ORIGINS" sep:";"`
+	Timeouts map[string]string `name:"DUMP_TIMEOUTS"`
+}
+
+func newDumpDummy() *dumpDummy {
+	addrs, _ := utils.ParseAddrs("localhost:123")
+
+	return &dumpDummy{
+		Host:     "localhost",
+		Password: "pass",
+		Token:    "token",
+		Public:   "public",
+		Timeout:  time.Second,
+		Addrs:    addrs,
+		Origins:  []string{"a.com", "b.com"},
+		Timeouts: map[string]string{"users": "1s", "friends": "2s"},
+	}
+}
+
+func TestTextDump(t *testing.T) {
+	dump, err := Dump(newDumpDummy(), DumpText)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := strings.Join([]string{
+		"DUMP_HOST=localhost",
+		"DUMP_PASSWORD_SECRET=" + RedactedValue,
+		"DUMP_TOKEN=" + RedactedValue,
+		"DUMP_PUBLIC_SECRET=public",
+		"DUMP_EMPTY_SECRET=",
+		"DUMP_TIMEOUT=1s",
+		"DUMP_ADDRS=localhost:123",
+		"DUMP_ORIGINS=a.com;b.com",
+		"DUMP_TIMEOUTS=friends=2s,users=1s",
+	}, "\n") + "\n"
+
+	if dump != expected {
+		t.Errorf("Expecting dump:\n%v\ngot:\n%v", expected, dump)
+	}
+}
+
+func TestJSONDump(t *testing.T) {
+	dump, err := Dump(newDumpDummy(), DumpJSON)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal([]byte(dump), &values); err != nil {
+		t.Errorf("Expecting valid JSON, got %v: %v", dump, err)
+		return
+	}
+
+	if values["DUMP_PASSWORD_SECRET"] != RedactedValue || values["DUMP_HOST"] != "localhost" {
+		t.Errorf("Unexpected dumped values: %v", values)
+	}
+
+	if strings.Index(dump, "DUMP_HOST") > strings.Index(dump, "DUMP_TIMEOUTS") {
+		t.Errorf("Expecting variables by the fields order, got %v", dump)
+	}
+}
+
+func TestInvalidDump(t *testing.T) {
+	if _, err := Dump(newDumpDummy(), DumpFormat(-1)); err != InvalidDumpFormatError {
+		t.Errorf("Expecting error InvalidDumpFormatError, got %v", err)
+	}
+
+	if _, err := Dump(dumpDummy{}, DumpText); err != InvalidPointerError {
+		t.Errorf("Expecting error InvalidPointerError, got %v", err)
+	}
+}
+
+func TestDumpWithNamespace(t *testing.T) {
+	setVars(map[string]string{"NS_DUMP_HOST": "localhost"})
+	defer unsetVars("NS_DUMP_HOST")
+
+	parser, _ := New(envvars.New(&FakeProvider{}), WithNamespace("NS"))
+
+	conf := &struct {
+		Host string `name:"DUMP_HOST"`
+	}{}
+	if err := parser.ParseConf(conf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dump, err := parser.Dump(conf, DumpText)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if expected := "NS_DUMP_HOST=localhost\n"; dump != expected {
+		t.Errorf("Expecting dump:\n%v\ngot:\n%v", expected, dump)
+	}
+}
+
+func TestOptionalDump(t *testing.T) {
+	zero := 0
+
+	dump, err := Dump(&struct {
+		A *int `name:"DUMP_OPTIONAL_1"`
+		B *int `name:"DUMP_OPTIONAL_2"`
+	}{A: &zero}, DumpText)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if expected := "DUMP_OPTIONAL_1=0\nDUMP_OPTIONAL_2=\n"; dump != expected {
+		t.Errorf("Expecting dump:\n%v\ngot:\n%v", expected, dump)
+	}
+}
+
+func TestRichTypesDump(t *testing.T) {
+	type Dummy struct {
+		Size     utils.ByteSize `name:"DUMP_SIZE"`
+		Endpoint url.URL        `name:"DUMP_ENDPOINT"`
+		Addr     netip.Addr     `name:"DUMP_ADDR"`
+		Level    slog.Level     `name:"DUMP_LEVEL"`
+		Location *time.Location `name:"DUMP_LOCATION"`
+		Since    time.Time      `name:"DUMP_SINCE"`
+	}
+
+	endpoint, _ := url.Parse("https://localhost/path")
+
+	dump, err := Dump(&Dummy{
+		Size:     64 * utils.MiB,
+		Endpoint: *endpoint,
+		Addr:     netip.MustParseAddr("10.0.0.1"),
+		Level:    slog.LevelWarn,
+		Location: time.UTC,
+		Since:    time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+	}, DumpText)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := strings.Join([]string{
+		"DUMP_SIZE=64MiB",
+		"DUMP_ENDPOINT=https://localhost/path",
+		"DUMP_ADDR=10.0.0.1",
+		"DUMP_LEVEL=WARN",
+		"DUMP_LOCATION=UTC",
+		"DUMP_SINCE=2023-05-01T10:00:00Z",
+	}, "\n") + "\n"
+
+	if dump != expected {
+		t.Errorf("Expecting dump:\n%v\ngot:\n%v", expected, dump)
+	}
+}
@@ -26,6 +26,11 @@ var MissingVariablesReader = errors.New("received nil varReader")
 var WithoutFieldsError = errors.New("struct doesn't have any field")
 var InvalidPointerError = errors.New("expecting pointer to struct")
 var InvalidValuePointedError = errors.New("expecting pointer referencing a non-nil struct")
+var InvalidDumpFormatError = errors.New("unknown dump format")
+var WithoutWatchTriggersError = errors.New("expecting an interval or at least one signal")
+var MissingConfParserError = errors.New("received nil config parser")
+var UnsupportedStrictModeError = errors.New("strict mode requires a provider that lists its variables")
+var NotParsedError = errors.New("no struct of this type was filled by the config parser")
 
 // PrivateFieldError represents a struct field
 // that is private which means is impossible
@@ -132,3 +137,137 @@ func (e *InvalidTagKeyValueFmtError) Error() string {
 		"struct field %v contains invalid value \"%v\" in tag key %v: %v",
 		e.fieldName, e.rawValue, e.keyName, e.reason)
 }
+
+// ConflictingTagKeysError represents a struct field
+// with tag keys that can't be used together
+type ConflictingTagKeysError struct {
+	fieldName string
+	keyNames  []string
+}
+
+func (e *ConflictingTagKeysError) Error() string {
+	return fmt.Sprintf(
+		"struct field %v can't have tag keys %v at the same time",
+		e.fieldName, strings.Join(e.keyNames, ","))
+}
+
+// InvalidCollectionFmtError represents a
+// bad formatted slice or map raw value
+type InvalidCollectionFmtError struct {
+	rawValue string
+	reason   string
+}
+
+func (e *InvalidCollectionFmtError) Error() string {
+	return fmt.Sprintf("invalid collection value \"%v\": %v", e.rawValue, e.reason)
+}
+
+// RepeatedConverterError represents a type
+// that already has a registered converter
+type RepeatedConverterError struct {
+	typeName string
+}
+
+func (e *RepeatedConverterError) Error() string {
+	return fmt.Sprintf("type %v already has a converter", e.typeName)
+}
+
+// VariablesError aggregates the errors of every variable that
+// couldn't be assigned. Each one is an errorw.Wrapper, keeping
+// the error code that describes its nature
+type VariablesError struct {
+	errs []error
+}
+
+func (e *VariablesError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf(
+		"%v invalid variable(s): %v", len(e.errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the aggregated errors, which
+// allows inspecting them with errors.Is and errors.As
+func (e *VariablesError) Unwrap() []error {
+	return e.Errors()
+}
+
+// Errors returns the aggregated errors, by the
+// same order of the struct fields. Each one
+// is guaranteed to be an errorw.Wrapper
+func (e *VariablesError) Errors() []error {
+	errs := make([]error, len(e.errs))
+	copy(errs, e.errs)
+
+	return errs
+}
+
+// ConstraintViolationError represents a value that
+// doesn't respect a constraint defined in a tag key
+type ConstraintViolationError struct {
+	keyName  string
+	bound    string
+	rawValue string
+}
+
+func (e *ConstraintViolationError) Error() string {
+	return fmt.Sprintf(
+		"value %v violates constraint %v=%v",
+		e.rawValue, e.keyName, e.bound)
+}
+
+// UnreloadableChangeError represents a variable whose value has
+// changed, but it was ignored since it can't be reloaded
+type UnreloadableChangeError struct {
+	varName   string
+	fieldPath string
+}
+
+func (e *UnreloadableChangeError) Error() string {
+	return fmt.Sprintf(
+		"variable %v of struct field %v has changed but can't be reloaded",
+		e.varName, e.fieldPath)
+}
+
+// UnknownVarReferenceError represents a tag key
+// that references a variable that doesn't exist
+type UnknownVarReferenceError struct {
+	fieldName string
+	keyName   string
+	varName   string
+}
+
+func (e *UnknownVarReferenceError) Error() string {
+	return fmt.Sprintf(
+		"tag key %v of struct field %v references unknown variable %v",
+		e.keyName, e.fieldName, e.varName)
+}
+
+// DeprecatedVarWarning represents a variable whose value was
+// only found under one of its aliases, which are deprecated
+type DeprecatedVarWarning struct {
+	Name      string // Variable's name
+	Alias     string // Deprecated name that defines the value
+	FieldPath string // Struct field path, e.g. Tls.Cert
+}
+
+func (e *DeprecatedVarWarning) Error() string {
+	return fmt.Sprintf(
+		"variable %v of struct field %v is deprecated, use %v instead",
+		e.Alias, e.FieldPath, e.Name)
+}
+
+// InvalidNamespaceError represents a namespace
+// that doesn't match the variable's name format
+type InvalidNamespaceError struct {
+	namespace string
+}
+
+func (e *InvalidNamespaceError) Error() string {
+	return fmt.Sprintf(
+		"invalid namespace %v; accepted pattern: %v",
+		e.namespace, varNameRegex.String())
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gen
+
+import (
+	"fmt"
+)
+
+// UnknownVariableError represents a rule of a
+// schema that references an unknown variable
+type UnknownVariableError struct {
+	varName string
+	ruleOf  string
+}
+
+func (e *UnknownVariableError) Error() string {
+	return fmt.Sprintf("variable %v references unknown variable %v", e.ruleOf, e.varName)
+}
+
+// InvalidPatternError represents a pattern
+// that isn't a valid regular expression
+type InvalidPatternError struct {
+	varName string
+	err     error
+}
+
+func (e *InvalidPatternError) Error() string {
+	return fmt.Sprintf("invalid pattern of variable %v: %v", e.varName, e.err)
+}
+
+func (e *InvalidPatternError) Unwrap() error {
+	return e.err
+}
+
+// ViolationError represents a value that doesn't respect
+// a constraint, where Key is the tag key that defines it
+type ViolationError struct {
+	Key   string
+	Bound string
+	Value string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("value %v violates constraint %v=%v", e.Value, e.Key, e.Bound)
+}
+
+// CollectionFormatError represents a
+// bad formatted slice or map raw value
+type CollectionFormatError struct {
+	RawValue string
+	Reason   string
+}
+
+func (e *CollectionFormatError) Error() string {
+	return fmt.Sprintf("invalid collection value \"%v\": %v", e.RawValue, e.Reason)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gen contains what the loaders generated by confgen rely on,
+// i.e. the schema of the variables and the parsers of the builtin types.
+// The loaders are handed to config.ConfParser.ParseGenerated, which
+// reads the variables and checks the rules like ConfParser.ParseConf
+package gen
+
+import (
+	"regexp"
+)
+
+// VarCondition is a condition NAME=value of required_if
+type VarCondition struct {
+	Name  string // Variable's name, including prefixes
+	Value string // Raw value of the condition
+}
+
+// VarSpec describes a variable of a config struct, as
+// parsed from its field tag. The specs of a schema are
+// written by confgen, by the same order of the fields
+type VarSpec struct {
+	Name       string   // Variable's name, including prefixes
+	Aliases    []string // Deprecated names, including prefixes
+	FieldPath  string   // Struct field path, e.g. Tls.Cert
+	Required   bool
+	Secret     bool
+	Default    string
+	HasDefault bool
+	Accepts    []string // Accepted keywords
+	Separator  string   // Separator of collection elements, empty if it isn't a collection
+	Map        bool     // Tells if the collection is a map
+	Pattern    string   // Regular expression that the raw value must match, if any
+	RequiredIf []VarCondition
+	Excludes   []string
+	Group      string
+}
+
+// Schema contains the variables of a config struct
+// filled by a generated loader, see NewSchema
+type Schema struct {
+	specs    []VarSpec
+	patterns []*regexp.Regexp
+}
+
+// NewSchema returns the schema of the variables described by specs.
+// Returns UnknownVariableError if some rule references an unknown
+// variable or InvalidPatternError if some pattern doesn't compile
+func NewSchema(specs []VarSpec) (*Schema, error) {
+	s := &Schema{specs: specs, patterns: make([]*regexp.Regexp, len(specs))}
+
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		names[spec.Name] = true
+	}
+
+	for i, spec := range specs {
+		for _, condition := range spec.RequiredIf {
+			if !names[condition.Name] {
+				return nil, &UnknownVariableError{varName: condition.Name, ruleOf: spec.Name}
+			}
+		}
+
+		for _, name := range spec.Excludes {
+			if !names[name] {
+				return nil, &UnknownVariableError{varName: name, ruleOf: spec.Name}
+			}
+		}
+
+		if spec.Pattern == "" {
+			continue
+		}
+
+		pattern, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return nil, &InvalidPatternError{varName: spec.Name, err: err}
+		}
+
+		s.patterns[i] = pattern
+	}
+
+	return s, nil
+}
+
+// Specs returns the specs of the schema variables
+func (s *Schema) Specs() []VarSpec {
+	return s.specs
+}
+
+// Match returns ViolationError if rawVal (or some collection
+// element) of the variable at position i doesn't match its
+// pattern. Maps are checked by the values of their entries
+func (s *Schema) Match(i int, rawVal string) error {
+	pattern := s.patterns[i]
+	if pattern == nil {
+		return nil
+	}
+
+	elems := []string{rawVal}
+
+	if spec := s.specs[i]; spec.Separator != "" {
+		var err error
+		if elems, err = SplitElements(rawVal, spec.Separator); err != nil {
+			return err
+		}
+
+		for j, entry := range elems {
+			if spec.Map {
+				if _, elems[j], err = SplitEntry(entry); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, elem := range elems {
+		if !pattern.MatchString(elem) {
+			return &ViolationError{Key: "pattern", Bound: pattern.String(), Value: elem}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gen
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The following parsers convert the raw value of a variable to each
+// builtin type. They back the converters of config.ConfParser as well
+
+// ParseString returns the raw value itself
+func ParseString(rawVal string) (string, error) {
+	return rawVal, nil
+}
+
+// ParseSigned tries to obtain a signed integer with the size of T
+func ParseSigned[T int | int8 | int16 | int32 | int64](rawVal string) (T, error) {
+	val, err := strconv.ParseInt(rawVal, 10, reflect.TypeOf(T(0)).Bits())
+
+	return T(val), err
+}
+
+// ParseUnsigned tries to obtain an unsigned integer with the size of T
+func ParseUnsigned[T uint | uint8 | uint16 | uint32 | uint64](rawVal string) (T, error) {
+	val, err := strconv.ParseUint(rawVal, 10, reflect.TypeOf(T(0)).Bits())
+
+	return T(val), err
+}
+
+// ParseFloat tries to obtain a floating point number with the size of T
+func ParseFloat[T float32 | float64](rawVal string) (T, error) {
+	val, err := strconv.ParseFloat(rawVal, reflect.TypeOf(T(0)).Bits())
+
+	return T(val), err
+}
+
+// ParseBool tries to obtain a bool, see strconv.ParseBool
+func ParseBool(rawVal string) (bool, error) {
+	return strconv.ParseBool(rawVal)
+}
+
+// ParseDuration tries to obtain a time.Duration, see time.ParseDuration
+func ParseDuration(rawVal string) (time.Duration, error) {
+	return time.ParseDuration(rawVal)
+}
+
+// ParseAddrs tries to obtain a list of addresses, see utils.ParseAddrs
+func ParseAddrs(rawVal string) (*utils.Addrs, error) {
+	return utils.ParseAddrs(rawVal)
+}
+
+// ParseByteSize tries to obtain a size, e.g. 64MiB, see utils.ParseByteSize
+func ParseByteSize(rawVal string) (utils.ByteSize, error) {
+	return utils.ParseByteSize(rawVal)
+}
+
+// ParseURL tries to obtain an absolute url.URL
+func ParseURL(rawVal string) (url.URL, error) {
+	val, err := url.Parse(rawVal)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	if !val.IsAbs() {
+		return url.URL{}, fmt.Errorf("url %v doesn't have a scheme", rawVal)
+	}
+
+	return *val, nil
+}
+
+// ParseAddr tries to obtain a netip.Addr
+func ParseAddr(rawVal string) (netip.Addr, error) {
+	return netip.ParseAddr(rawVal)
+}
+
+// ParsePrefix tries to obtain a netip.Prefix (CIDR)
+func ParsePrefix(rawVal string) (netip.Prefix, error) {
+	return netip.ParsePrefix(rawVal)
+}
+
+// ParseLogLevel tries to obtain a slog.Level, e.g. INFO or warn+2
+func ParseLogLevel(rawVal string) (slog.Level, error) {
+	var val slog.Level
+
+	err := val.UnmarshalText([]byte(rawVal))
+
+	return val, err
+}
+
+// ParseLocation tries to obtain a *time.Location, see time.LoadLocation
+func ParseLocation(rawVal string) (*time.Location, error) {
+	return time.LoadLocation(rawVal)
+}
+
+// ParseTime tries to obtain a RFC3339 time.Time
+func ParseTime(rawVal string) (time.Time, error) {
+	return time.Parse(time.RFC3339, rawVal)
+}
+
+// parsers contains the name of the parser of each builtin type. Generic
+// parsers are instantiated by the type of the value they're assigned to
+var parsers = map[reflect.Type]string{
+	reflect.TypeOf(""):                    "ParseString",
+	reflect.TypeOf(0):                     "ParseSigned",
+	reflect.TypeOf(int8(0)):               "ParseSigned",
+	reflect.TypeOf(int16(0)):              "ParseSigned",
+	reflect.TypeOf(int32(0)):              "ParseSigned",
+	reflect.TypeOf(int64(0)):              "ParseSigned",
+	reflect.TypeOf(uint(0)):               "ParseUnsigned",
+	reflect.TypeOf(uint8(0)):              "ParseUnsigned",
+	reflect.TypeOf(uint16(0)):             "ParseUnsigned",
+	reflect.TypeOf(uint32(0)):             "ParseUnsigned",
+	reflect.TypeOf(uint64(0)):             "ParseUnsigned",
+	reflect.TypeOf(float32(0)):            "ParseFloat",
+	reflect.TypeOf(float64(0)):            "ParseFloat",
+	reflect.TypeOf(time.Duration(0)):      "ParseDuration",
+	reflect.TypeOf(false):                 "ParseBool",
+	reflect.TypeOf((*utils.Addrs)(nil)):   "ParseAddrs",
+	reflect.TypeOf(utils.ByteSize(0)):     "ParseByteSize",
+	reflect.TypeOf(url.URL{}):             "ParseURL",
+	reflect.TypeOf(netip.Addr{}):          "ParseAddr",
+	reflect.TypeOf(netip.Prefix{}):        "ParsePrefix",
+	reflect.TypeOf(slog.Level(0)):         "ParseLogLevel",
+	reflect.TypeOf((*time.Location)(nil)): "ParseLocation",
+	reflect.TypeOf(time.Time{}):           "ParseTime",
+}
+
+// LoaderTypes returns the types supported by generated loaders, i.e.
+// the builtin ones. Fields can be optionals, slices or maps of them
+func LoaderTypes() []reflect.Type {
+	types := make([]reflect.Type, 0, len(parsers))
+	for t := range parsers {
+		types = append(types, t)
+	}
+
+	return types
+}
+
+// ParserName returns the name of the parser of t in this package.
+// Returns false if t isn't supported by generated loaders
+func ParserName(t reflect.Type) (string, bool) {
+	name, ok := parsers[t]
+
+	return name, ok
+}
+
+// keyValueSeparator splits the key from the value of a map entry
+const keyValueSeparator = "="
+
+// SplitElements splits a raw collection value with sep. Returns
+// CollectionFormatError if the value has some empty element
+func SplitElements(rawVal, sep string) ([]string, error) {
+	elems, ok := utils.SplitList(rawVal, sep)
+	if !ok {
+		return nil, &CollectionFormatError{
+			RawValue: rawVal,
+			Reason:   fmt.Sprintf("expecting non-empty elements separated by \"%v\"", sep),
+		}
+	}
+
+	return elems, nil
+}
+
+// SplitEntry splits a map entry in the format key=value. Returns
+// CollectionFormatError if the key or the value are missing
+func SplitEntry(rawEntry string) (string, string, error) {
+	key, val, found := strings.Cut(rawEntry, keyValueSeparator)
+	key, val = utils.PolishString(key), utils.PolishString(val)
+
+	if !found || key == "" || val == "" {
+		return "", "", &CollectionFormatError{
+			RawValue: rawEntry,
+			Reason: fmt.Sprintf(
+				"expecting map entry in the format key%vvalue", keyValueSeparator),
+		}
+	}
+
+	return key, val, nil
+}
+
+// Parse sets dst to the value obtained by parse. It's left
+// untouched if rawVal is invalid
+func Parse[T any](dst *T, rawVal string, parse func(rawVal string) (T, error)) error {
+	val, err := parse(rawVal)
+	if err == nil {
+		*dst = val
+	}
+
+	return err
+}
+
+// ParseOptional sets dst to a new value obtained by
+// parse. It's left untouched if rawVal is invalid
+func ParseOptional[T any](dst **T, rawVal string, parse func(rawVal string) (T, error)) error {
+	val, err := parse(rawVal)
+	if err == nil {
+		*dst = &val
+	}
+
+	return err
+}
+
+// ParseSlice sets dst to the elements of rawVal, separated by sep,
+// where each one is converted by parse. It's left untouched if some
+// element is invalid. Returns CollectionFormatError if rawVal is
+// bad formatted
+func ParseSlice[S ~[]E, E any](dst *S, rawVal, sep string, parse func(rawVal string) (E, error)) error {
+	elems, err := SplitElements(rawVal, sep)
+	if err != nil {
+		return err
+	}
+
+	slice := make(S, len(elems))
+
+	for i, elem := range elems {
+		if slice[i], err = parse(elem); err != nil {
+			return err
+		}
+	}
+
+	*dst = slice
+
+	return nil
+}
+
+// ParseMap sets dst to the entries of rawVal, separated by sep, in the
+// format key=value, where each value is converted by parse. It's left
+// untouched if some entry is invalid. Returns CollectionFormatError
+// if rawVal is bad formatted or some key is repeated
+func ParseMap[M ~map[string]V, V any](dst *M, rawVal, sep string, parse func(rawVal string) (V, error)) error {
+	entries, err := SplitElements(rawVal, sep)
+	if err != nil {
+		return err
+	}
+
+	newMap := make(M, len(entries))
+
+	for _, entry := range entries {
+		key, rawElem, err := SplitEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := newMap[key]; ok {
+			return &CollectionFormatError{
+				RawValue: rawVal,
+				Reason:   fmt.Sprintf("repeated key %v", key),
+			}
+		}
+
+		if newMap[key], err = parse(rawElem); err != nil {
+			return err
+		}
+	}
+
+	*dst = newMap
+
+	return nil
+}
+
+// MustParse returns the value obtained by parse, panicking if rawVal is
+// invalid. Generated loaders use it for bounds validated by confgen
+func MustParse[T any](parse func(rawVal string) (T, error), rawVal string) T {
+	val, err := parse(rawVal)
+	if err != nil {
+		panic(fmt.Sprintf("gen: invalid value %v: %v", rawVal, err))
+	}
+
+	return val
+}
+
+// MinViolation returns the ViolationError of
+// val, which is lower than the bound min
+func MinViolation(rawBound string, val any) error {
+	return &ViolationError{Key: "min", Bound: rawBound, Value: fmt.Sprint(val)}
+}
+
+// MaxViolation returns the ViolationError of
+// val, which is greater than the bound max
+func MaxViolation(rawBound string, val any) error {
+	return &ViolationError{Key: "max", Bound: rawBound, Value: fmt.Sprint(val)}
+}
+
+// CheckLen returns ViolationError if length isn't between minLen
+// and maxLen (inclusive), where maxLen is -1 when unbounded
+func CheckLen(length, minLen, maxLen int) error {
+	if length >= minLen && (maxLen == -1 || length <= maxLen) {
+		return nil
+	}
+
+	var bound string
+	switch {
+	case minLen == maxLen:
+		bound = strconv.Itoa(minLen)
+	case maxLen == -1:
+		bound = fmt.Sprintf("%v..", minLen)
+	default:
+		bound = fmt.Sprintf("%v..%v", minLen, maxLen)
+	}
+
+	return &ViolationError{Key: "len", Bound: bound, Value: fmt.Sprintf("with length %v", length)}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSlice(t *testing.T) {
+	testBattery := []struct {
+		rawVal   string
+		expected []int
+		fails    bool
+	}{
+		{"1,2,3", []int{1, 2, 3}, false},
+		{" 4 ", []int{4}, false},
+		{"1,,3", []int{9}, true},
+		{"1,x", []int{9}, true},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.rawVal, func(t *testing.T) {
+			val := []int{9}
+
+			err := ParseSlice(&val, pair.rawVal, ",", ParseSigned[int])
+			if pair.fails != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(val, pair.expected) {
+				t.Errorf("Expecting %v, got %v", pair.expected, val)
+			}
+		})
+	}
+}
+
+func TestParseMap(t *testing.T) {
+	testBattery := []struct {
+		rawVal   string
+		expected map[string]time.Duration
+		fails    bool
+	}{
+		{"a=1s;b=2m", map[string]time.Duration{"a": time.Second, "b": 2 * time.Minute}, false},
+		{"a=1s;a=2s", nil, true},
+		{"a;b=1s", nil, true},
+		{"a=x", nil, true},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.rawVal, func(t *testing.T) {
+			var val map[string]time.Duration
+
+			err := ParseMap(&val, pair.rawVal, ";", ParseDuration)
+			if pair.fails != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(val, pair.expected) {
+				t.Errorf("Expecting %v, got %v", pair.expected, val)
+			}
+		})
+	}
+}
+
+func TestParseOptional(t *testing.T) {
+	var val *bool
+
+	if err := ParseOptional(&val, "maybe", ParseBool); err == nil || val != nil {
+		t.Errorf("Expecting error and nil value, got %v and %v", err, val)
+	}
+
+	if err := ParseOptional(&val, "true", ParseBool); err != nil || val == nil || !*val {
+		t.Errorf("Expecting true, got %v and error %v", val, err)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if val := MustParse(ParseUnsigned[uint8], "255"); val != 255 {
+		t.Errorf("Expecting 255, got %v", val)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expecting panic on invalid value")
+		}
+	}()
+
+	MustParse(ParseUnsigned[uint8], "256")
+}
+
+func TestNewSchemaInvalidSpecs(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		specs    []VarSpec
+		expected any
+	}{
+		{"unknown excluded", []VarSpec{
+			{Name: "HOST", FieldPath: "Host", Excludes: []string{"PORT"}},
+		}, new(*UnknownVariableError)},
+		{"unknown condition", []VarSpec{
+			{Name: "HOST", FieldPath: "Host", RequiredIf: []VarCondition{{Name: "PORT", Value: "1"}}},
+		}, new(*UnknownVariableError)},
+		{"invalid pattern", []VarSpec{
+			{Name: "HOST", FieldPath: "Host", Pattern: "("},
+		}, new(*InvalidPatternError)},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			if _, err := NewSchema(pair.specs); !errors.As(err, pair.expected) {
+				t.Errorf("Expecting error of type %T, got %v", pair.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchemaMatch(t *testing.T) {
+	schema, err := NewSchema([]VarSpec{
+		{Name: "HOST", FieldPath: "Host", Pattern: "^h"},
+		{Name: "TAGS", FieldPath: "Tags", Pattern: "^t", Separator: ";"},
+		{Name: "FLAGS", FieldPath: "Flags", Pattern: "^f", Separator: ",", Map: true},
+		{Name: "PORT", FieldPath: "Port"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testBattery := []struct {
+		i      int
+		rawVal string
+		fails  bool
+	}{
+		{0, "host", false},
+		{0, "other", true},
+		{1, "t1;t2", false},
+		{1, "t1;x", true},
+		{2, "a=f1,b=f2", false},
+		{2, "f=x", true},
+		{3, "anything", false},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.rawVal, func(t *testing.T) {
+			err := schema.Match(pair.i, pair.rawVal)
+			if pair.fails != (err != nil) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			var violation *ViolationError
+			if err != nil && (!errors.As(err, &violation) || violation.Key != "pattern") {
+				t.Errorf("Expecting pattern ViolationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckLen(t *testing.T) {
+	testBattery := []struct {
+		length, minLen, maxLen int
+		expected               string
+	}{
+		{2, 1, 3, ""},
+		{4, 1, 3, "value with length 4 violates constraint len=1..3"},
+		{0, 1, -1, "value with length 0 violates constraint len=1.."},
+		{3, 2, 2, "value with length 3 violates constraint len=2"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.expected, func(t *testing.T) {
+			err := CheckLen(pair.length, pair.minLen, pair.maxLen)
+			if (err == nil) != (pair.expected == "") || err != nil && err.Error() != pair.expected {
+				t.Errorf("Expecting %q, got %v", pair.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gotypes builds the reflect.Type equivalent to a type checked
+// by go/types, so that config structs can be evaluated by the config
+// package without being compiled, e.g. by code generators and analyzers
+package gotypes
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+)
+
+// Resolver returns the reflect.Type of t if it's converted as a whole,
+// e.g. time.Duration. Otherwise, returns false so that t is built from
+// its structure. The field at path can be used in errors
+type Resolver func(t types.Type, path string) (reflect.Type, bool, error)
+
+// basicTypes maps the basic types supported by the config parser
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.String:  reflect.TypeOf(""),
+	types.Bool:    reflect.TypeOf(false),
+	types.Int:     reflect.TypeOf(0),
+	types.Int8:    reflect.TypeOf(int8(0)),
+	types.Int16:   reflect.TypeOf(int16(0)),
+	types.Int32:   reflect.TypeOf(int32(0)),
+	types.Int64:   reflect.TypeOf(int64(0)),
+	types.Uint:    reflect.TypeOf(uint(0)),
+	types.Uint8:   reflect.TypeOf(uint8(0)),
+	types.Uint16:  reflect.TypeOf(uint16(0)),
+	types.Uint32:  reflect.TypeOf(uint32(0)),
+	types.Uint64:  reflect.TypeOf(uint64(0)),
+	types.Float32: reflect.TypeOf(float32(0)),
+	types.Float64: reflect.TypeOf(float64(0)),
+}
+
+// unmarshalers contains the interfaces equivalent to
+// config.Unmarshaler and encoding.TextUnmarshaler
+var unmarshalers = func() []*types.Interface {
+	errorType := types.Universe.Lookup("error").Type()
+
+	newInterface := func(method string, param types.Type) *types.Interface {
+		signature := types.NewSignatureType(nil, nil, nil,
+			types.NewTuple(types.NewParam(token.NoPos, nil, "", param)),
+			types.NewTuple(types.NewParam(token.NoPos, nil, "", errorType)), false)
+
+		return types.NewInterfaceType([]*types.Func{
+			types.NewFunc(token.NoPos, nil, method, signature),
+		}, nil).Complete()
+	}
+
+	return []*types.Interface{
+		newInterface("UnmarshalConfig", types.Typ[types.String]),
+		newInterface("UnmarshalText", types.NewSlice(types.Typ[types.Byte])),
+	}
+}()
+
+// IsUnmarshaler tells if t (or a pointer to t) implements config.Unmarshaler
+// or encoding.TextUnmarshaler, i.e. if its values are parsed by its methods
+func IsUnmarshaler(t types.Type) bool {
+	for _, u := range unmarshalers {
+		if types.Implements(t, u) || types.Implements(types.NewPointer(t), u) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TypeKey returns the name of t qualified by its package
+// path, e.g. *time.Location, which matches types.TypeString
+func TypeKey(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + TypeKey(t.Elem())
+	}
+
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
+
+// TypeOf returns the equivalent reflect.Type of t, where structs are built
+// with reflect.StructOf and other named types must be known by resolve.
+// The field at path is used in errors
+func TypeOf(t types.Type, path string, resolve Resolver) (reflect.Type, error) {
+	t = types.Unalias(t)
+
+	if rType, ok, err := resolve(t, path); ok || err != nil {
+		return rType, err
+	}
+
+	switch u := t.(type) {
+	case *types.Basic:
+		if rType, ok := basicTypes[u.Kind()]; ok {
+			return rType, nil
+		}
+	case *types.Pointer:
+		elem, err := TypeOf(u.Elem(), path, resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.PointerTo(elem), nil
+	case *types.Slice:
+		elem, err := TypeOf(u.Elem(), path, resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.SliceOf(elem), nil
+	case *types.Map:
+		key, err := TypeOf(u.Key(), path, resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		elem, err := TypeOf(u.Elem(), path, resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.MapOf(key, elem), nil
+	case *types.Named:
+		// Named collections are handled like unnamed ones
+		switch under := u.Underlying().(type) {
+		case *types.Struct:
+			return StructOf(under, path+".", resolve)
+		case *types.Slice, *types.Map:
+			return TypeOf(under, path, resolve)
+		}
+	case *types.Struct:
+		return StructOf(u, path+".", resolve)
+	}
+
+	return nil, fmt.Errorf("struct field %v contains unsupported type %v", path, t)
+}
+
+// StructOf returns the equivalent struct type of srt, keeping names, tags
+// and embedded fields. Private fields and embedded fields that aren't
+// structs are rejected, as done by the config parser, given that
+// reflect.StructOf can't build them. Fields paths are prefixed by path
+func StructOf(srt *types.Struct, path string, resolve Resolver) (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, srt.NumFields())
+
+	for i := 0; i < srt.NumFields(); i++ {
+		field := srt.Field(i)
+
+		// Only embedded structs are allowed
+		if _, ok := field.Type().Underlying().(*types.Struct); field.Embedded() && !ok {
+			return nil, fmt.Errorf("field %v is anonymous", path+field.Name())
+		}
+
+		if !field.Exported() {
+			return nil, fmt.Errorf(
+				"field %v is private, it's impossible to assign a value", path+field.Name())
+		}
+
+		fieldType, err := TypeOf(field.Type(), path+field.Name(), resolve)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name:      field.Name(),
+			Type:      fieldType,
+			Tag:       reflect.StructTag(srt.Tag(i)),
+			Anonymous: field.Embedded(),
+		})
+	}
+
+	return reflect.StructOf(fields), nil
+}
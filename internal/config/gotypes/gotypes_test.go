@@ -0,0 +1,186 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gotypes
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dummySrc = `package dummy
+
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error { return nil }
+
+type Name string
+
+func (n Name) UnmarshalConfig(rawVal string) error { return nil }
+
+type Tags []string
+
+type Nested struct {
+	Port uint16 ` + "`name:\"PORT\"`" + `
+}
+
+type Dummy struct {
+	Nested
+	Host  *string
+	Tags  Tags
+	Flags map[string]bool
+}
+
+type Invalid struct {
+	Ch chan int
+}
+
+type creds struct {
+	User string
+}
+
+type Private struct {
+	level int
+}
+
+type PrivateEmbedded struct {
+	X int
+	creds
+}
+
+type AnonymousName struct {
+	Name
+}
+`
+
+func dummyPackage(t *testing.T) *types.Package {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "dummy.go", dummySrc, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pkg, err := (&types.Config{}).Check("dummy", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return pkg
+}
+
+func noResolver(types.Type, string) (reflect.Type, bool, error) {
+	return nil, false, nil
+}
+
+func TestIsUnmarshaler(t *testing.T) {
+	pkg := dummyPackage(t)
+
+	testBattery := []struct {
+		typeName string
+		expected bool
+	}{
+		{"Level", true},
+		{"Name", true},
+		{"Tags", false},
+		{"Dummy", false},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.typeName, func(t *testing.T) {
+			named := pkg.Scope().Lookup(pair.typeName).Type()
+
+			if got := IsUnmarshaler(named); got != pair.expected {
+				t.Errorf("Expecting %v, got %v", pair.expected, got)
+			}
+
+			if got := IsUnmarshaler(types.NewPointer(named)); got != pair.expected {
+				t.Errorf("Expecting %v for pointer, got %v", pair.expected, got)
+			}
+		})
+	}
+}
+
+func TestTypeKey(t *testing.T) {
+	testBattery := []struct {
+		rType    reflect.Type
+		expected string
+	}{
+		{reflect.TypeOf(0), "int"},
+		{reflect.TypeOf(time.Duration(0)), "time.Duration"},
+		{reflect.TypeOf((*time.Location)(nil)), "*time.Location"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.expected, func(t *testing.T) {
+			if got := TypeKey(pair.rType); got != pair.expected {
+				t.Errorf("Expecting %v, got %v", pair.expected, got)
+			}
+		})
+	}
+}
+
+func TestStructOf(t *testing.T) {
+	pkg := dummyPackage(t)
+
+	srt := pkg.Scope().Lookup("Dummy").Type().Underlying().(*types.Struct)
+
+	rType, err := StructOf(srt, "", noResolver)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := `struct { struct { Port uint16 "name:\"PORT\"" }; Host *string; Tags []string; Flags map[string]bool }`
+	if rType.String() != expected {
+		t.Errorf("Expecting %v, got %v", expected, rType)
+	}
+
+	if field := rType.Field(0); !field.Anonymous || field.Name != "Nested" {
+		t.Errorf("Expecting embedded field Nested, got %+v", field)
+	}
+}
+
+func TestStructOfInvalidStructs(t *testing.T) {
+	pkg := dummyPackage(t)
+
+	testBattery := []struct {
+		name     string
+		expected string
+	}{
+		{"Invalid", "struct field Ch contains unsupported type chan int"},
+		{"Private", "field level is private, it's impossible to assign a value"},
+		{"PrivateEmbedded", "field creds is private, it's impossible to assign a value"},
+		{"AnonymousName", "field Name is anonymous"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			srt := pkg.Scope().Lookup(pair.name).Type().Underlying().(*types.Struct)
+
+			_, err := StructOf(srt, "", noResolver)
+			if err == nil || !strings.Contains(err.Error(), pair.expected) {
+				t.Errorf("Expecting error %v, got %v", pair.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// FieldBound is the value of the tag key min or max of a field,
+// converted like the field value (or like each of its elements)
+type FieldBound struct {
+	Value reflect.Value
+	Raw   string
+}
+
+// FieldCondition is a condition of required_if, where Target is the
+// position of the referenced variable and Value is converted like it
+type FieldCondition struct {
+	Target int
+	Value  reflect.Value
+}
+
+// FieldSpec describes a variable of a config struct like gen.VarSpec,
+// with the parsed constraints and conditions, so that confgen can
+// write loaders that parse it like ConfParser.ParseConf
+type FieldSpec struct {
+	gen.VarSpec
+	Type           reflect.Type // Field type
+	Collection     reflect.Kind // Slice or Map if it's a collection, Invalid otherwise
+	Optional       bool         // Tells if the field points to an optional value
+	HasLen         bool
+	MinLen, MaxLen int
+	Min, Max       *FieldBound
+	Conditions     []FieldCondition // Conditions of required_if, by the same order
+}
+
+// FieldSpecs parses the config struct of type sType and describes each
+// one of its variables, by the same order of the struct fields. Returns
+// the same struct related errors as ConfParser.ParseConf
+func FieldSpecs(sType reflect.Type) ([]*FieldSpec, error) {
+	if sType == nil || sType.Kind() != reflect.Struct {
+		return nil, InvalidValuePointedError
+	}
+
+	vars, err := parseFields(sType)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[*variableInfo]int, len(vars))
+	for i, v := range vars {
+		positions[v] = i
+	}
+
+	specs := make([]*FieldSpec, len(vars))
+
+	for i, v := range vars {
+		accepts := v.validKeywords()
+		sort.Strings(accepts)
+
+		spec := &FieldSpec{
+			VarSpec: gen.VarSpec{
+				Name:       v.name,
+				Aliases:    v.aliases,
+				FieldPath:  v.fieldPath,
+				Required:   v.required,
+				Secret:     v.secret,
+				Default:    v.defaultValue,
+				HasDefault: v.hasDefault,
+				Accepts:    accepts,
+				Map:        v.collection == reflect.Map,
+			},
+			Type:       v.fieldType,
+			Collection: v.collection,
+			Optional:   v.collection == reflect.Invalid && isOptional(v.fieldType),
+		}
+
+		if v.collection != reflect.Invalid {
+			spec.Separator = v.collectionSeparator()
+		}
+
+		if c := v.constraints; c != nil {
+			spec.HasLen, spec.MinLen, spec.MaxLen = c.hasLen, c.minLen, c.maxLen
+
+			if c.min != nil {
+				spec.Min = &FieldBound{Value: *c.min, Raw: c.rawMin}
+			}
+			if c.max != nil {
+				spec.Max = &FieldBound{Value: *c.max, Raw: c.rawMax}
+			}
+			if c.pattern != nil {
+				spec.Pattern = c.pattern.String()
+			}
+		}
+
+		if r := v.rules; r != nil {
+			for _, condition := range r.requiredIf {
+				spec.RequiredIf = append(spec.RequiredIf,
+					gen.VarCondition{Name: condition.name, Value: condition.rawValue})
+				spec.Conditions = append(spec.Conditions,
+					FieldCondition{Target: positions[condition.target], Value: condition.value})
+			}
+
+			spec.Excludes, spec.Group = r.excludes, r.group
+		}
+
+		specs[i] = spec
+	}
+
+	return specs, nil
+}
+
+// generatedSchema contains the variables of a gen.Schema, where
+// conditions maps each condition of required_if to its position
+type generatedSchema struct {
+	vars       []*variableInfo
+	conditions map[*varCondition]int
+}
+
+// generatedSchemas caches the variables of each schema
+var generatedSchemas sync.Map // map[*gen.Schema]*generatedSchema
+
+// newGeneratedSchema converts the specs of schema to the
+// variables read by the parser, by the same order
+func newGeneratedSchema(schema *gen.Schema) *generatedSchema {
+	specs := schema.Specs()
+
+	gs := &generatedSchema{conditions: make(map[*varCondition]int)}
+	byName := make(map[string]*variableInfo, len(specs))
+
+	for _, spec := range specs {
+		v := &variableInfo{
+			fieldPath:      spec.FieldPath,
+			name:           spec.Name,
+			aliases:        spec.Aliases,
+			required:       spec.Required,
+			secret:         spec.Secret,
+			defaultValue:   spec.Default,
+			hasDefault:     spec.HasDefault,
+			separator:      spec.Separator,
+			acceptedValues: utils.NewSet[string](),
+		}
+
+		for _, keyword := range spec.Accepts {
+			v.acceptedValues.Put(keyword)
+		}
+
+		switch {
+		case spec.Separator == "":
+		case spec.Map:
+			v.collection = reflect.Map
+		default:
+			v.collection = reflect.Slice
+		}
+
+		gs.vars = append(gs.vars, v)
+		byName[v.name] = v
+	}
+
+	// Names were validated by gen.NewSchema
+	for i, spec := range specs {
+		if spec.RequiredIf == nil && spec.Excludes == nil && spec.Group == "" {
+			continue
+		}
+
+		r := &varRules{group: spec.Group}
+
+		for _, condition := range spec.RequiredIf {
+			c := &varCondition{
+				name:     condition.Name,
+				rawValue: condition.Value,
+				target:   byName[condition.Name],
+			}
+
+			gs.conditions[c] = len(gs.conditions)
+			r.requiredIf = append(r.requiredIf, c)
+		}
+
+		for _, name := range spec.Excludes {
+			r.excludes = append(r.excludes, name)
+			r.excluded = append(r.excluded, byName[name])
+		}
+
+		gs.vars[i].rules = r
+	}
+
+	return gs
+}
+
+// cachedGeneratedSchema returns the cached variables of schema,
+// converting them on the first call
+func cachedGeneratedSchema(schema *gen.Schema) *generatedSchema {
+	if gs, ok := generatedSchemas.Load(schema); ok {
+		return gs.(*generatedSchema)
+	}
+
+	gs, _ := generatedSchemas.LoadOrStore(schema, newGeneratedSchema(schema))
+
+	return gs.(*generatedSchema)
+}
+
+// fromGenerated converts the errors returned by
+// the gen package to the ones of the parser
+func fromGenerated(err error) error {
+	var violation *gen.ViolationError
+	var collection *gen.CollectionFormatError
+
+	switch {
+	case errors.As(err, &violation):
+		return &ConstraintViolationError{
+			keyName:  violation.Key,
+			bound:    violation.Bound,
+			rawValue: violation.Value,
+		}
+	case errors.As(err, &collection):
+		return &InvalidCollectionFmtError{
+			rawValue: collection.RawValue,
+			reason:   collection.Reason,
+		}
+	default:
+		return err
+	}
+}
+
+// ParseGenerated fills from like ParseConf, where the variables are described
+// by schema and their raw values are converted and assigned by fill, i.e.
+// without reflection. fill receives the position of the variable in schema
+// and only assigns the field if the value is valid. holds tells if each
+// condition of required_if holds, by the order of schema, once all fields
+// are filled, and may be nil if there isn't any. It's meant to be called
+// by the loaders generated by confgen
+func (cp *ConfParser) ParseGenerated(
+	from StructPtr,
+	schema *gen.Schema,
+	fill func(i int, rawVal string) error,
+	holds func() []bool,
+) error {
+	srtVal, err := extractStrVal(from)
+	if err != nil {
+		return err
+	}
+
+	gs := cachedGeneratedSchema(schema)
+
+	fillValue := func(i int, _ *variableInfo, rawVal string) error {
+		return fromGenerated(fill(i, rawVal))
+	}
+
+	// Conditions are evaluated once all fields are filled
+	var conditions []bool
+
+	conditionHolds := func(c *varCondition) bool {
+		if conditions == nil {
+			conditions = holds()
+		}
+
+		return conditions[gs.conditions[c]]
+	}
+
+	origins, err := cp.fillFields(gs.vars, fillValue, conditionHolds)
+	cp.recordOrigins(srtVal.Type(), origins)
+
+	return err
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"reflect"
+	"testing"
+)
+
+func TestFieldSpecs(t *testing.T) {
+	type dummy struct {
+		Mode  string            `name:"MODE" accepts:"b,a" default:"a"`
+		Port  *uint16           `name:"PORT" min:"1" max:"9000"`
+		Tags  []string          `name:"TAGS" len:"1..3" sep:";"`
+		Flags map[string]bool   `name:"FLAGS"`
+		Token string            `name:"TOKEN" required_if:"PORT=443" pattern:"^t"`
+		Other map[string]string `name:"OTHER" excludes:"TAGS"`
+	}
+
+	specs, err := FieldSpecs(reflect.TypeOf(dummy{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(specs) != 6 {
+		t.Fatalf("Expecting 6 specs, got %v", len(specs))
+	}
+
+	mode, port, tags, flags, token, other := specs[0], specs[1], specs[2], specs[3], specs[4], specs[5]
+
+	if !reflect.DeepEqual(mode.Accepts, []string{"a", "b"}) || !mode.HasDefault || mode.Default != "a" {
+		t.Errorf("Expecting sorted keywords and default, got %+v", mode.VarSpec)
+	}
+
+	if !port.Optional || port.Min == nil || port.Min.Raw != "1" || reflect.Indirect(port.Max.Value).Uint() != 9000 {
+		t.Errorf("Expecting optional with bounds, got %+v", port)
+	}
+
+	if tags.Collection != reflect.Slice || tags.Separator != ";" || !tags.HasLen || tags.MaxLen != 3 {
+		t.Errorf("Expecting slice with length, got %+v", tags)
+	}
+
+	if flags.Collection != reflect.Map || !flags.Map || flags.Separator != defaultSeparator {
+		t.Errorf("Expecting map with default separator, got %+v", flags)
+	}
+
+	if token.Pattern != "^t" || len(token.Conditions) != 1 || token.Conditions[0].Target != 1 ||
+		token.RequiredIf[0] != (gen.VarCondition{Name: "PORT", Value: "443"}) ||
+		reflect.Indirect(token.Conditions[0].Value).Uint() != 443 {
+		t.Errorf("Expecting pattern and condition on PORT, got %+v", token)
+	}
+
+	if !reflect.DeepEqual(other.Excludes, []string{"TAGS"}) {
+		t.Errorf("Expecting excludes TAGS, got %v", other.Excludes)
+	}
+}
+
+func TestFieldSpecsNotStruct(t *testing.T) {
+	if _, err := FieldSpecs(reflect.TypeOf(0)); err != InvalidValuePointedError {
+		t.Errorf("Expecting error InvalidValuePointedError, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadertest declares config structs that cover every feature of
+// the config parser, whose generated loaders are checked against
+// config.ConfParser.ParseConf
+package loadertest
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"time"
+)
+
+//go:generate go run ../../../cmd/confgen -type Fixture,Minimal -output fixture_loader.go
+
+// Endpoint is a nested struct
+type Endpoint struct {
+	Host string `name:"HOST" required:"yes" pattern:"^[a-z0-9.-]+$"`
+	Port uint16 `name:"PORT" min:"1" default:"8080"`
+}
+
+// Credentials is an embedded struct
+type Credentials struct {
+	User     string `name:"USER_SECRET" len:"1..16" required_if:"MODE=secure"`
+	Password string `name:"PASSWORD_SECRET" excludes:"TOKEN_SECRET"`
+	Token    string `name:"TOKEN_SECRET"`
+}
+
+// Fixture declares a variable of each supported type and tag
+type Fixture struct {
+	Mode      string            `name:"MODE" accepts:"plain,secure" default:"plain"`
+	Level     slog.Level        `name:"LEVEL" default:"INFO" max:"ERROR"`
+	Retries   *int              `name:"RETRIES" aliases:"RETRY,TRIES" min:"0" max:"10"`
+	Ratio     float32           `name:"RATIO" min:"0.1" max:"1"`
+	Factor    *float64          `name:"FACTOR"`
+	Offset    int8              `name:"OFFSET" min:"-5"`
+	Big       int64             `name:"BIG"`
+	Count     uint              `name:"COUNT"`
+	Small     uint8             `name:"SMALL" max:"200"`
+	Mid       int16             `name:"MID"`
+	Wide      int32             `name:"WIDE"`
+	Huge      uint64            `name:"HUGE"`
+	Half      uint32            `name:"HALF"`
+	Timeout   time.Duration     `name:"TIMEOUT" min:"1s" max:"1h" default:"30s"`
+	Debug     bool              `name:"DEBUG"`
+	Verbose   *bool             `name:"VERBOSE"`
+	Limit     utils.ByteSize    `name:"LIMIT" max:"1GiB"`
+	Nodes     *utils.Addrs      `name:"NODES"`
+	Proxy     url.URL           `name:"PROXY"`
+	Bind      netip.Addr        `name:"BIND" min:"10.0.0.1"`
+	Network   netip.Prefix      `name:"NETWORK"`
+	Zone      *time.Location    `name:"ZONE"`
+	Since     time.Time         `name:"SINCE" min:"2020-01-01T00:00:00Z"`
+	Tags      []string          `name:"TAGS" sep:";" len:"..3" accepts:"a,b,c"`
+	Weights   []int             `name:"WEIGHTS" min:"1" pattern:"^[0-9]+$"`
+	Limits    map[string]uint16 `name:"LIMITS" max:"100"`
+	Labels    map[string]string `name:"LABELS" sep:"|" len:"1.."`
+	Nickname  *string           `name:"NICKNAME" len:"2..8"`
+	Api       Endpoint          `prefix:"API_"`
+	Admin     Endpoint          `prefix:"ADMIN_" reload:"no"`
+	Gateway   netip.Addr        `name:"GATEWAY" required_if:"BIND=10.0.0.1,RETRIES=3,DEBUG=false"`
+	Primary   string            `name:"PRIMARY" one_of_group:"SOURCE"`
+	Secondary string            `name:"SECONDARY" one_of_group:"SOURCE"`
+	Credentials
+}
+
+// Minimal is a struct with a single variable
+type Minimal struct {
+	Name string `name:"MINIMAL_NAME"`
+}
@@ -0,0 +1,671 @@
+// Code generated by confgen. DO NOT EDIT.
+
+package loadertest
+
+import (
+	conf "github.com/franciscosbf/micro-dwarf/internal/config"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"unicode/utf8"
+)
+
+// Values of bounds and conditions of Fixture without a literal
+var (
+	fixtureMin19      = gen.MustParse(gen.ParseAddr, "10.0.0.1")
+	fixtureMin22      = gen.MustParse(gen.ParseTime, "2020-01-01T00:00:00Z")
+	fixtureCondition0 = gen.MustParse(gen.ParseAddr, "10.0.0.1")
+)
+
+// fixtureSchema describes the variables of Fixture
+var fixtureSchema, fixtureSchemaErr = gen.NewSchema([]gen.VarSpec{
+	{
+		Name:       "MODE",
+		FieldPath:  "Mode",
+		Default:    "plain",
+		HasDefault: true,
+		Accepts:    []string{"plain", "secure"},
+	},
+	{
+		Name:       "LEVEL",
+		FieldPath:  "Level",
+		Default:    "INFO",
+		HasDefault: true,
+	},
+	{
+		Name:      "RETRIES",
+		FieldPath: "Retries",
+		Aliases:   []string{"RETRY", "TRIES"},
+	},
+	{
+		Name:      "RATIO",
+		FieldPath: "Ratio",
+	},
+	{
+		Name:      "FACTOR",
+		FieldPath: "Factor",
+	},
+	{
+		Name:      "OFFSET",
+		FieldPath: "Offset",
+	},
+	{
+		Name:      "BIG",
+		FieldPath: "Big",
+	},
+	{
+		Name:      "COUNT",
+		FieldPath: "Count",
+	},
+	{
+		Name:      "SMALL",
+		FieldPath: "Small",
+	},
+	{
+		Name:      "MID",
+		FieldPath: "Mid",
+	},
+	{
+		Name:      "WIDE",
+		FieldPath: "Wide",
+	},
+	{
+		Name:      "HUGE",
+		FieldPath: "Huge",
+	},
+	{
+		Name:      "HALF",
+		FieldPath: "Half",
+	},
+	{
+		Name:       "TIMEOUT",
+		FieldPath:  "Timeout",
+		Default:    "30s",
+		HasDefault: true,
+	},
+	{
+		Name:      "DEBUG",
+		FieldPath: "Debug",
+	},
+	{
+		Name:      "VERBOSE",
+		FieldPath: "Verbose",
+	},
+	{
+		Name:      "LIMIT",
+		FieldPath: "Limit",
+	},
+	{
+		Name:      "NODES",
+		FieldPath: "Nodes",
+	},
+	{
+		Name:      "PROXY",
+		FieldPath: "Proxy",
+	},
+	{
+		Name:      "BIND",
+		FieldPath: "Bind",
+	},
+	{
+		Name:      "NETWORK",
+		FieldPath: "Network",
+	},
+	{
+		Name:      "ZONE",
+		FieldPath: "Zone",
+	},
+	{
+		Name:      "SINCE",
+		FieldPath: "Since",
+	},
+	{
+		Name:      "TAGS",
+		FieldPath: "Tags",
+		Accepts:   []string{"a", "b", "c"},
+		Separator: ";",
+	},
+	{
+		Name:      "WEIGHTS",
+		FieldPath: "Weights",
+		Separator: ",",
+		Pattern:   "^[0-9]+$",
+	},
+	{
+		Name:      "LIMITS",
+		FieldPath: "Limits",
+		Separator: ",",
+		Map:       true,
+	},
+	{
+		Name:      "LABELS",
+		FieldPath: "Labels",
+		Separator: "|",
+		Map:       true,
+	},
+	{
+		Name:      "NICKNAME",
+		FieldPath: "Nickname",
+	},
+	{
+		Name:      "API_HOST",
+		FieldPath: "Api.Host",
+		Required:  true,
+		Pattern:   "^[a-z0-9.-]+$",
+	},
+	{
+		Name:       "API_PORT",
+		FieldPath:  "Api.Port",
+		Default:    "8080",
+		HasDefault: true,
+	},
+	{
+		Name:      "ADMIN_HOST",
+		FieldPath: "Admin.Host",
+		Required:  true,
+		Pattern:   "^[a-z0-9.-]+$",
+	},
+	{
+		Name:       "ADMIN_PORT",
+		FieldPath:  "Admin.Port",
+		Default:    "8080",
+		HasDefault: true,
+	},
+	{
+		Name:      "GATEWAY",
+		FieldPath: "Gateway",
+		RequiredIf: []gen.VarCondition{
+			{Name: "BIND", Value: "10.0.0.1"},
+			{Name: "RETRIES", Value: "3"},
+			{Name: "DEBUG", Value: "false"},
+		},
+	},
+	{
+		Name:      "PRIMARY",
+		FieldPath: "Primary",
+		Group:     "SOURCE",
+	},
+	{
+		Name:      "SECONDARY",
+		FieldPath: "Secondary",
+		Group:     "SOURCE",
+	},
+	{
+		Name:      "USER_SECRET",
+		FieldPath: "Credentials.User",
+		Secret:    true,
+		RequiredIf: []gen.VarCondition{
+			{Name: "MODE", Value: "secure"},
+		},
+	},
+	{
+		Name:      "PASSWORD_SECRET",
+		FieldPath: "Credentials.Password",
+		Secret:    true,
+		Excludes:  []string{"TOKEN_SECRET"},
+	},
+	{
+		Name:      "TOKEN_SECRET",
+		FieldPath: "Credentials.Token",
+		Secret:    true,
+	},
+})
+
+// Load fills c with the variables read from vReader, like
+// conf.ConfParser.ParseConf, but without reflection. opts
+// configure the parser, e.g. conf.WithNamespace
+func (c *Fixture) Load(vReader *envvars.VarReader, opts ...conf.Option) error {
+	cp, err := conf.New(vReader, opts...)
+	if err != nil {
+		return err
+	}
+
+	return c.LoadWith(cp)
+}
+
+// LoadWith fills c with the variables read by cp, like
+// cp.ParseConf, but without reflection
+func (c *Fixture) LoadWith(cp *conf.ConfParser) error {
+	if fixtureSchemaErr != nil {
+		return fixtureSchemaErr
+	}
+
+	fill := func(i int, rawVal string) error {
+		switch i {
+		case 0: // MODE
+			val := c.Mode
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Mode = val
+			}
+
+			return err
+		case 1: // LEVEL
+			val := c.Level
+			err := gen.Parse(&val, rawVal, gen.ParseLogLevel)
+			if err == nil && val > 8 {
+				err = gen.MaxViolation("ERROR", val)
+			}
+			if err == nil {
+				c.Level = val
+			}
+
+			return err
+		case 2: // RETRIES
+			val := c.Retries
+			err := gen.ParseOptional(&val, rawVal, gen.ParseSigned)
+			if err == nil && *val < 0 {
+				err = gen.MinViolation("0", *val)
+			}
+			if err == nil && *val > 10 {
+				err = gen.MaxViolation("10", *val)
+			}
+			if err == nil {
+				c.Retries = val
+			}
+
+			return err
+		case 3: // RATIO
+			val := c.Ratio
+			err := gen.Parse(&val, rawVal, gen.ParseFloat)
+			if err == nil && val < 0.10000000149011612 {
+				err = gen.MinViolation("0.1", val)
+			}
+			if err == nil && val > 1 {
+				err = gen.MaxViolation("1", val)
+			}
+			if err == nil {
+				c.Ratio = val
+			}
+
+			return err
+		case 4: // FACTOR
+			val := c.Factor
+			err := gen.ParseOptional(&val, rawVal, gen.ParseFloat)
+			if err == nil {
+				c.Factor = val
+			}
+
+			return err
+		case 5: // OFFSET
+			val := c.Offset
+			err := gen.Parse(&val, rawVal, gen.ParseSigned)
+			if err == nil && val < -5 {
+				err = gen.MinViolation("-5", val)
+			}
+			if err == nil {
+				c.Offset = val
+			}
+
+			return err
+		case 6: // BIG
+			val := c.Big
+			err := gen.Parse(&val, rawVal, gen.ParseSigned)
+			if err == nil {
+				c.Big = val
+			}
+
+			return err
+		case 7: // COUNT
+			val := c.Count
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil {
+				c.Count = val
+			}
+
+			return err
+		case 8: // SMALL
+			val := c.Small
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil && val > 200 {
+				err = gen.MaxViolation("200", val)
+			}
+			if err == nil {
+				c.Small = val
+			}
+
+			return err
+		case 9: // MID
+			val := c.Mid
+			err := gen.Parse(&val, rawVal, gen.ParseSigned)
+			if err == nil {
+				c.Mid = val
+			}
+
+			return err
+		case 10: // WIDE
+			val := c.Wide
+			err := gen.Parse(&val, rawVal, gen.ParseSigned)
+			if err == nil {
+				c.Wide = val
+			}
+
+			return err
+		case 11: // HUGE
+			val := c.Huge
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil {
+				c.Huge = val
+			}
+
+			return err
+		case 12: // HALF
+			val := c.Half
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil {
+				c.Half = val
+			}
+
+			return err
+		case 13: // TIMEOUT
+			val := c.Timeout
+			err := gen.Parse(&val, rawVal, gen.ParseDuration)
+			if err == nil && val < 1000000000 {
+				err = gen.MinViolation("1s", val)
+			}
+			if err == nil && val > 3600000000000 {
+				err = gen.MaxViolation("1h", val)
+			}
+			if err == nil {
+				c.Timeout = val
+			}
+
+			return err
+		case 14: // DEBUG
+			val := c.Debug
+			err := gen.Parse(&val, rawVal, gen.ParseBool)
+			if err == nil {
+				c.Debug = val
+			}
+
+			return err
+		case 15: // VERBOSE
+			val := c.Verbose
+			err := gen.ParseOptional(&val, rawVal, gen.ParseBool)
+			if err == nil {
+				c.Verbose = val
+			}
+
+			return err
+		case 16: // LIMIT
+			val := c.Limit
+			err := gen.Parse(&val, rawVal, gen.ParseByteSize)
+			if err == nil && val > 1073741824 {
+				err = gen.MaxViolation("1GiB", val)
+			}
+			if err == nil {
+				c.Limit = val
+			}
+
+			return err
+		case 17: // NODES
+			val := c.Nodes
+			err := gen.Parse(&val, rawVal, gen.ParseAddrs)
+			if err == nil {
+				c.Nodes = val
+			}
+
+			return err
+		case 18: // PROXY
+			val := c.Proxy
+			err := gen.Parse(&val, rawVal, gen.ParseURL)
+			if err == nil {
+				c.Proxy = val
+			}
+
+			return err
+		case 19: // BIND
+			val := c.Bind
+			err := gen.Parse(&val, rawVal, gen.ParseAddr)
+			if err == nil && val.Compare(fixtureMin19) < 0 {
+				err = gen.MinViolation("10.0.0.1", val)
+			}
+			if err == nil {
+				c.Bind = val
+			}
+
+			return err
+		case 20: // NETWORK
+			val := c.Network
+			err := gen.Parse(&val, rawVal, gen.ParsePrefix)
+			if err == nil {
+				c.Network = val
+			}
+
+			return err
+		case 21: // ZONE
+			val := c.Zone
+			err := gen.Parse(&val, rawVal, gen.ParseLocation)
+			if err == nil {
+				c.Zone = val
+			}
+
+			return err
+		case 22: // SINCE
+			val := c.Since
+			err := gen.Parse(&val, rawVal, gen.ParseTime)
+			if err == nil && val.Compare(fixtureMin22) < 0 {
+				err = gen.MinViolation("2020-01-01T00:00:00Z", val)
+			}
+			if err == nil {
+				c.Since = val
+			}
+
+			return err
+		case 23: // TAGS
+			val := c.Tags
+			err := gen.ParseSlice(&val, rawVal, ";", gen.ParseString)
+			if err == nil {
+				err = gen.CheckLen(len(val), 0, 3)
+			}
+			if err == nil {
+				c.Tags = val
+			}
+
+			return err
+		case 24: // WEIGHTS
+			val := c.Weights
+			err := gen.ParseSlice(&val, rawVal, ",", gen.ParseSigned)
+			for _, e := range val {
+				if err == nil && e < 1 {
+					err = gen.MinViolation("1", e)
+				}
+			}
+			if err == nil {
+				err = fixtureSchema.Match(24, rawVal)
+			}
+			if err == nil {
+				c.Weights = val
+			}
+
+			return err
+		case 25: // LIMITS
+			val := c.Limits
+			err := gen.ParseMap(&val, rawVal, ",", gen.ParseUnsigned)
+			for _, e := range val {
+				if err == nil && e > 100 {
+					err = gen.MaxViolation("100", e)
+				}
+			}
+			if err == nil {
+				c.Limits = val
+			}
+
+			return err
+		case 26: // LABELS
+			val := c.Labels
+			err := gen.ParseMap(&val, rawVal, "|", gen.ParseString)
+			if err == nil {
+				err = gen.CheckLen(len(val), 1, -1)
+			}
+			if err == nil {
+				c.Labels = val
+			}
+
+			return err
+		case 27: // NICKNAME
+			val := c.Nickname
+			err := gen.ParseOptional(&val, rawVal, gen.ParseString)
+			if err == nil {
+				err = gen.CheckLen(utf8.RuneCountInString(*val), 2, 8)
+			}
+			if err == nil {
+				c.Nickname = val
+			}
+
+			return err
+		case 28: // API_HOST
+			val := c.Api.Host
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				err = fixtureSchema.Match(28, rawVal)
+			}
+			if err == nil {
+				c.Api.Host = val
+			}
+
+			return err
+		case 29: // API_PORT
+			val := c.Api.Port
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil && val < 1 {
+				err = gen.MinViolation("1", val)
+			}
+			if err == nil {
+				c.Api.Port = val
+			}
+
+			return err
+		case 30: // ADMIN_HOST
+			val := c.Admin.Host
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				err = fixtureSchema.Match(30, rawVal)
+			}
+			if err == nil {
+				c.Admin.Host = val
+			}
+
+			return err
+		case 31: // ADMIN_PORT
+			val := c.Admin.Port
+			err := gen.Parse(&val, rawVal, gen.ParseUnsigned)
+			if err == nil && val < 1 {
+				err = gen.MinViolation("1", val)
+			}
+			if err == nil {
+				c.Admin.Port = val
+			}
+
+			return err
+		case 32: // GATEWAY
+			val := c.Gateway
+			err := gen.Parse(&val, rawVal, gen.ParseAddr)
+			if err == nil {
+				c.Gateway = val
+			}
+
+			return err
+		case 33: // PRIMARY
+			val := c.Primary
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Primary = val
+			}
+
+			return err
+		case 34: // SECONDARY
+			val := c.Secondary
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Secondary = val
+			}
+
+			return err
+		case 35: // USER_SECRET
+			val := c.Credentials.User
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				err = gen.CheckLen(utf8.RuneCountInString(val), 1, 16)
+			}
+			if err == nil {
+				c.Credentials.User = val
+			}
+
+			return err
+		case 36: // PASSWORD_SECRET
+			val := c.Credentials.Password
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Credentials.Password = val
+			}
+
+			return err
+		case 37: // TOKEN_SECRET
+			val := c.Credentials.Token
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Credentials.Token = val
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	holds := func() []bool {
+		return []bool{
+			c.Bind == fixtureCondition0,         // GATEWAY required if BIND=10.0.0.1
+			c.Retries != nil && *c.Retries == 3, // GATEWAY required if RETRIES=3
+			!c.Debug,                            // GATEWAY required if DEBUG=false
+			c.Mode == "secure",                  // USER_SECRET required if MODE=secure
+		}
+	}
+
+	return cp.ParseGenerated(c, fixtureSchema, fill, holds)
+}
+
+// minimalSchema describes the variables of Minimal
+var minimalSchema, minimalSchemaErr = gen.NewSchema([]gen.VarSpec{
+	{
+		Name:      "MINIMAL_NAME",
+		FieldPath: "Name",
+	},
+})
+
+// Load fills c with the variables read from vReader, like
+// conf.ConfParser.ParseConf, but without reflection. opts
+// configure the parser, e.g. conf.WithNamespace
+func (c *Minimal) Load(vReader *envvars.VarReader, opts ...conf.Option) error {
+	cp, err := conf.New(vReader, opts...)
+	if err != nil {
+		return err
+	}
+
+	return c.LoadWith(cp)
+}
+
+// LoadWith fills c with the variables read by cp, like
+// cp.ParseConf, but without reflection
+func (c *Minimal) LoadWith(cp *conf.ConfParser) error {
+	if minimalSchemaErr != nil {
+		return minimalSchemaErr
+	}
+
+	fill := func(i int, rawVal string) error {
+		switch i {
+		case 0: // MINIMAL_NAME
+			val := c.Name
+			err := gen.Parse(&val, rawVal, gen.ParseString)
+			if err == nil {
+				c.Name = val
+			}
+
+			return err
+		}
+
+		return nil
+	}
+
+	return cp.ParseGenerated(c, minimalSchema, fill, nil)
+}
@@ -17,11 +17,16 @@ limitations under the License.
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 	"github.com/franciscosbf/micro-dwarf/internal/errorw"
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // StructPtr represents any struct pointer
@@ -33,22 +38,108 @@ const (
 	ErrorCodeMissingVar
 	ErrorCodeUnacceptedVal
 	ErrorCodeInvalidVarType
+	ErrorCodeConstraintViolation
+	ErrorCodeConflictingVars
+	ErrorCodeUnreadableFile
+	ErrorCodeUnknownVar
 )
 
 // ConfParser represents a client config that
 // fetches variables from a given reader
 type ConfParser struct {
-	reader *envvars.VarReader
+	reader    *envvars.VarReader
+	namespace string
+	strict    bool
+	onWarning func(err error)
+
+	// origins maps each parsed struct type to the
+	// origins of the variables of the last one parsed
+	origins sync.Map // map[reflect.Type][]*valueOrigin
+}
+
+// Option configures a ConfParser
+type Option func(cp *ConfParser)
+
+// WithWarningHandler replaces the handler of warnings, which receives
+// DeprecatedVarWarning. By default, warnings are logged. If handler is
+// nil, warnings are discarded. The handler may be called concurrently,
+// since ConfParser.ParseConf is safe to be called from many goroutines
+func WithWarningHandler(handler func(err error)) Option {
+	return func(cp *ConfParser) {
+		if handler == nil {
+			handler = discardHandler
+		}
+
+		cp.onWarning = handler
+	}
+}
+
+// namespaceSeparator joins the namespace and the variable's name
+const namespaceSeparator = "_"
+
+// WithNamespace prepends namespace to the name of every variable (including
+// aliases and the ones that hold paths of secrets), joined by an underscore.
+// E.g. POSTGRES_HOST is read from USERS_POSTGRES_HOST if namespace is USERS.
+// It must respect the variable's name format
+func WithNamespace(namespace string) Option {
+	return func(cp *ConfParser) {
+		cp.namespace = utils.PolishString(namespace)
+	}
+}
+
+// WithStrict reports the variables of the config reader that aren't read
+// by any field, whose name starts with the prefix of some variable of the
+// struct, i.e. its name up to the first underscore (e.g. POSTGRES_). The
+// config reader provider must implement envvars.Enumerator
+func WithStrict() Option {
+	return func(cp *ConfParser) {
+		cp.strict = true
+	}
+}
+
+// varName returns the name of the variable in the config reader
+func (cp *ConfParser) varName(name string) string {
+	if cp.namespace == "" {
+		return name
+	}
+
+	return cp.namespace + namespaceSeparator + name
 }
 
-// variableInfo contains all parsed info from a
-// struct field. It's used to evaluate a variable
+// parserLogHandler is the default handler of warnings
+func parserLogHandler(err error) {
+	log.Printf("config parser: %v", err)
+}
+
+// variableInfo contains all parsed info from a struct field. It's
+// used to evaluate a variable. It only depends on the struct type,
+// i.e. it's shared by every value of it and must not be changed
 type variableInfo struct {
+	owner          reflect.Type
+	fieldName      string
+	fieldPath      string
+	fieldType      reflect.Type
+	index          []int
 	name           string
+	aliases        []string
 	required       bool
+	secret         bool
+	reloadable     bool
+	defaultValue   string
+	hasDefault     bool
+	separator      string
+	collection     reflect.Kind
 	acceptedValues *utils.Set[string]
-	val            *reflect.Value
+	constraints    *valueConstraints
+	rules          *varRules
 	setValue       typeConverter
+	elemSetValue   typeConverter
+}
+
+// fieldOf returns the field of the variable in srtVal,
+// which must be a value of the struct type that owns it
+func (v *variableInfo) fieldOf(srtVal *reflect.Value) reflect.Value {
+	return srtVal.FieldByIndex(v.index)
 }
 
 // isValidKeyword checks if a given value matches one of the accepted
@@ -57,6 +148,78 @@ func (v *variableInfo) isValidKeyword(val string) bool {
 	return v.acceptedValues.Empty() || v.acceptedValues.Contains(val)
 }
 
+// collectionKind returns reflect.Slice or reflect.Map if fieldT
+// is a collection, otherwise returns reflect.Invalid
+func collectionKind(fieldT reflect.Type) reflect.Kind {
+	if !isCollection(fieldT) {
+		return reflect.Invalid
+	}
+
+	return fieldT.Kind()
+}
+
+// keywords returns the values of rawVal that are checked against the
+// accepted keywords. Collections are split into each element, where
+// maps contribute with their entries values. If rawVal is a bad
+// formatted collection, returns nil, since its converter will fail
+func (v *variableInfo) keywords(rawVal string) []string {
+	if v.collection == reflect.Invalid {
+		return []string{rawVal}
+	}
+
+	elems, err := splitElements(rawVal, v.collectionSeparator())
+	if err != nil {
+		return nil
+	}
+
+	if v.collection == reflect.Map {
+		for i, entry := range elems {
+			if _, elems[i], err = splitEntry(entry); err != nil {
+				return nil
+			}
+		}
+	}
+
+	return elems
+}
+
+// unacceptedKeyword returns the first value of rawVal
+// that doesn't match any accepted keyword, if any
+func (v *variableInfo) unacceptedKeyword(rawVal string) (string, bool) {
+	for _, keyword := range v.keywords(rawVal) {
+		if !v.isValidKeyword(keyword) {
+			return keyword, true
+		}
+	}
+
+	return "", false
+}
+
+// names returns the variable's name followed by its aliases
+func (v *variableInfo) names() []string {
+	return append([]string{v.name}, v.aliases...)
+}
+
+// collectionSeparator returns the separator of collection
+// elements, falling back to the default one if not defined
+func (v *variableInfo) collectionSeparator() string {
+	if v.separator == "" {
+		return defaultSeparator
+	}
+
+	return v.separator
+}
+
+// checkConstraints verifies if val, converted from
+// rawVal, respects the field constraints (if any)
+func (v *variableInfo) checkConstraints(val reflect.Value, rawVal string) error {
+	if v.constraints == nil {
+		return nil
+	}
+
+	return v.constraints.check(val, v.keywords(rawVal))
+}
+
 // validKeywords returns a slice of accepted keywords
 func (v *variableInfo) validKeywords() []string {
 	return v.acceptedValues.Values()
@@ -82,65 +245,118 @@ func extractStrVal(possibleSrt StructPtr) (*reflect.Value, error) {
 
 // isAssignable tells if a given
 // struct field is exported or not
-func isAssignable(field *reflect.Value) bool {
-	return field.CanSet()
+func isAssignable(field *reflect.StructField) bool {
+	return field.IsExported()
 }
 
 // selectTypeConverter searches in the types converter repository if someone
 // matches the field type. If not, then means that is an unsupported type,
-// returning an error. Assumes that the field value representation was already
-// assigned to v
+// returning an error. Assumes that the collection separator (if any) was
+// already assigned to v
 func selectTypeConverter(v *variableInfo, field *reflect.StructField) error {
 	// Searches for the corresponding converter
-	if converter := selectConverter(v.val); converter != nil {
+	if converter := selectConverter(field.Type, v.collectionSeparator()); converter != nil {
 		v.setValue = converter
+		v.elemSetValue = selectElemConverter(field.Type)
 
 		return nil
 	}
 
 	return &UnsupportedTypeError{
 		fieldName: field.Name,
-		typeName:  field.Type.Name(),
+		typeName:  field.Type.String(),
 	}
 }
 
 // parseFieldTagKeys tries to parse each tag key.
 // Returns an error on the first invalid tag element.
-// The error that may come directly from this func
-// is RepeatedVarNameError, in case of a variable's
-// name was already assigned to another struct field
+// The variable's name is prepended with prefix. The
+// error that may come directly from this func is
+// RepeatedVarNameError, in case of a variable's name
+// was already assigned to another struct field
 func parseFieldTagKeys(
 	v *variableInfo,
 	field *reflect.StructField,
+	prefix string,
 	parsedNames map[string]string,
 ) (err error) {
 	if v.name, err = parseTagKeyName(field); err != nil {
 		return
 	}
+	v.name = prefix + v.name
 
-	if assignedField, ok := parsedNames[v.name]; ok {
-		return &RepeatedVarNameError{
-			assignedFieldName: assignedField,
-			varName:           v.name,
-		}
+	if v.aliases, err = parseTagKeyAliases(field); err != nil {
+		return
+	}
+	for i, alias := range v.aliases {
+		v.aliases[i] = prefix + alias
 	}
-	// Otherwise, caches it
-	parsedNames[v.name] = field.Name
 
 	if v.required, err = parseTagKeyRequired(field); err != nil {
 		return
 	}
 
+	if v.secret, err = parseTagKeySecret(field, v.name); err != nil {
+		return
+	}
+
+	// Aliases can't collide with other names either. Secrets can
+	// be read from the file referenced by another variable
+	for _, name := range v.names() {
+		if err = registerVarName(parsedNames, name, v.fieldPath); err != nil {
+			return
+		}
+
+		if v.secret {
+			if err = registerVarName(parsedNames, name+fileSuffix, v.fieldPath); err != nil {
+				return
+			}
+		}
+	}
+
+	if v.reloadable, err = parseTagKeyReload(field); err != nil {
+		return
+	}
+
+	if v.defaultValue, v.hasDefault, err = parseTagKeyDefault(field); err != nil {
+		return
+	}
+
+	// A required variable never falls back to its default
+	if v.required && v.hasDefault {
+		return &ConflictingTagKeysError{
+			fieldName: field.Name,
+			keyNames:  []string{requiredTagKey, defaultTagKey},
+		}
+	}
+
 	v.acceptedValues, err = parseTagKeyAccepts(field)
 
 	return
 }
 
+// registerVarName caches the name of a variable assigned to fieldPath.
+// Returns RepeatedVarNameError if it was already assigned to some field
+func registerVarName(parsedNames map[string]string, name, fieldPath string) error {
+	if assignedField, ok := parsedNames[name]; ok {
+		return &RepeatedVarNameError{
+			assignedFieldName: assignedField,
+			varName:           name,
+		}
+	}
+
+	parsedNames[name] = fieldPath
+
+	return nil
+}
+
 // validateAccepted checks if each one matches
-// the field type by converting it with the
-// required parser. Returns TypeInconsistencyError
-// if some value has a different type
+// the type of the field fieldName by converting
+// it with the required parser. Returns
+// TypeInconsistencyError if some value has a
+// different type
 func validateAccepted(
+	fieldName string,
 	fieldT reflect.Type,
 	parser typeConverter,
 	accepts *utils.Set[string],
@@ -151,8 +367,8 @@ func validateAccepted(
 	for _, v := range accepts.Values() {
 		if err := parser(&dummyField, v); err != nil {
 			return &TypeInconsistencyError{
-				fieldName: fieldT.Name(),
-				typeName:  fieldT.Name(),
+				fieldName: fieldName,
+				typeName:  fieldT.String(),
 				rawValue:  v,
 			}
 		}
@@ -161,57 +377,187 @@ func validateAccepted(
 	return nil
 }
 
-// parseFields iterates over each struct field, evaluating its type
-// and tag elements. Returns a slice containing info of all struct
-// variables. Upon some error while evaluating a field, it's returned
-// immediately after have received it. Errors from this function (not
-// the ones that might be returned from other calls) are WithoutFieldsError,
-// PrivateFieldError and AnonymousFieldError.
-func parseFields(strInfo *reflect.Value) ([]*variableInfo, error) {
-	sType := strInfo.Type()
-	fieldsNum := strInfo.NumField()
+// isNestedStruct tells if a given struct field type is a struct
+// without a type converter, i.e. it must be parsed recursively
+func isNestedStruct(fieldT reflect.Type) bool {
+	return fieldT.Kind() == reflect.Struct &&
+		selectConverter(fieldT, defaultSeparator) == nil
+}
+
+// validateSeparator checks if the separator (if any) was
+// given to a collection. Map separators can't be equal to
+// keyValueSeparator. Returns InvalidTagKeyValueFmtError
+// if the separator is invalid
+func validateSeparator(fieldT reflect.Type, v *variableInfo) error {
+	if v.separator == "" {
+		return nil
+	}
+
+	reason := ""
+	switch {
+	case !isCollection(fieldT):
+		reason = "separator is only allowed in slices and maps"
+	case fieldT.Kind() == reflect.Map && v.separator == keyValueSeparator:
+		reason = fmt.Sprintf("map separator can't be %v", keyValueSeparator)
+	default:
+		return nil
+	}
+
+	return &InvalidTagKeyValueFmtError{
+		fieldName: v.fieldPath,
+		keyName:   sepTagKey,
+		rawValue:  v.separator,
+		reason:    reason,
+	}
+}
+
+// validateDefault checks if the default value (if any) matches the
+// field type by converting it with the required parser, if it's one
+// of the accepted keywords and if it respects the constraints. Returns InvalidTagKeyValueFmtError
+// if the value is invalid
+func validateDefault(fieldT reflect.Type, v *variableInfo) error {
+	if !v.hasDefault {
+		return nil
+	}
+
+	// Used to try to assign the value
+	dummyField := reflect.New(fieldT).Elem()
+
+	if err := v.setValue(&dummyField, v.defaultValue); err != nil {
+		return &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   defaultTagKey,
+			rawValue:  v.defaultValue,
+			reason:    fmt.Sprintf("doesn't match type %v", fieldT.Name()),
+		}
+	}
+
+	if keyword, ok := v.unacceptedKeyword(v.defaultValue); ok {
+		return &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   defaultTagKey,
+			rawValue:  v.defaultValue,
+			reason:    fmt.Sprintf("%v isn't one of the accepted values", keyword),
+		}
+	}
+
+	if err := v.checkConstraints(dummyField, v.defaultValue); err != nil {
+		return &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   defaultTagKey,
+			rawValue:  v.defaultValue,
+			reason:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// parseStructFields iterates over each field of sType, evaluating its type
+// and tag elements. Nested structs are evaluated recursively, where the prefix
+// defined in their tag is appended to the given one and index locates them in
+// the root struct. If reloadable is false, none of the variables can be changed
+// on reload. Variable names are cached in parsedNames to detect repeated ones
+// across the whole struct tree. Upon some error while evaluating a field, it's
+// returned immediately after have received it
+func parseStructFields(
+	sType reflect.Type,
+	prefix, path string,
+	index []int,
+	reloadable bool,
+	parsedNames map[string]string,
+) ([]*variableInfo, error) {
+	fieldsNum := sType.NumField()
 
 	if fieldsNum == 0 {
 		return nil, WithoutFieldsError
 	}
 
-	// Cache to look for repeated variable names
-	parsedNames := make(map[string]string)
-
 	var fields []*variableInfo
 
 	// Extracts info from each struct field
 	for i := 0; i < fieldsNum; i++ {
-		fieldV := strInfo.Field(i)
 		fieldSt := sType.Field(i)
+		fieldPath := path + fieldSt.Name
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
 
-		if fieldSt.Anonymous {
+		// Only embedded structs are allowed
+		if fieldSt.Anonymous && fieldSt.Type.Kind() != reflect.Struct {
 			return nil, &AnonymousFieldError{
-				fieldName: fieldSt.Name,
+				fieldName: fieldPath,
 			}
 		}
 
-		if !isAssignable(&fieldV) {
-			return nil, &PrivateFieldError{fieldName: fieldSt.Name}
+		if !isAssignable(&fieldSt) {
+			return nil, &PrivateFieldError{fieldName: fieldPath}
 		}
 
-		newVar := &variableInfo{}
+		if isNestedStruct(fieldSt.Type) {
+			nestedPrefix, err := parseTagKeyPrefix(&fieldSt)
+			if err != nil {
+				return nil, err
+			}
 
-		// Set value representation
-		newVar.val = &fieldV
+			nestedReloadable, err := parseTagKeyReload(&fieldSt)
+			if err != nil {
+				return nil, err
+			}
+
+			nested, err := parseStructFields(
+				fieldSt.Type, prefix+nestedPrefix, fieldPath+".", fieldIndex,
+				reloadable && nestedReloadable, parsedNames)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, nested...)
+
+			continue
+		}
+
+		newVar := &variableInfo{
+			owner:      sType,
+			fieldName:  fieldSt.Name,
+			fieldPath:  fieldPath,
+			fieldType:  fieldSt.Type,
+			index:      fieldIndex,
+			collection: collectionKind(fieldSt.Type),
+		}
+
+		// Collection separator is required to select the converter
+		separator, err := parseTagKeySep(&fieldSt)
+		if err != nil {
+			return nil, err
+		}
+		newVar.separator = separator
 
 		// Set elements according to type representation
 		if err := selectTypeConverter(newVar, &fieldSt); err != nil {
 			return nil, err
 		}
-		if err := parseFieldTagKeys(newVar, &fieldSt, parsedNames); err != nil {
+		if err := parseFieldTagKeys(newVar, &fieldSt, prefix, parsedNames); err != nil {
 			return nil, err
 		}
+		newVar.reloadable = newVar.reloadable && reloadable
 
 		fieldT := fieldSt.Type
-		converter := newVar.setValue
+		if err := validateSeparator(fieldT, newVar); err != nil {
+			return nil, err
+		}
+
+		// Accepted keywords are matched against each collection element
+		converter := newVar.elemSetValue
 		keywords := newVar.acceptedValues
-		if err := validateAccepted(fieldT, converter, keywords); err != nil {
+		if err := validateAccepted(fieldPath, elemType(fieldT), converter, keywords); err != nil {
+			return nil, err
+		}
+		if err := parseFieldConstraints(newVar, &fieldSt); err != nil {
+			return nil, err
+		}
+		if err := validateDefault(fieldT, newVar); err != nil {
+			return nil, err
+		}
+		if err := parseFieldRules(newVar, &fieldSt, prefix); err != nil {
 			return nil, err
 		}
 
@@ -221,44 +567,315 @@ func parseFields(strInfo *reflect.Value) ([]*variableInfo, error) {
 	return fields, nil
 }
 
-// fillFields iterates over each field and evaluates the read
-// value from the config reader, according to the parsed info.
-// Lastly, tries to parse the raw value and set it into the field
-func (cp *ConfParser) fillFields(vars []*variableInfo) error {
-	for _, v := range vars {
-		vName := v.name
+// parseFields iterates over each field of sType, evaluating its type
+// and tag elements. Returns a slice containing info of all struct
+// variables, including the ones of nested structs. Upon some error
+// while evaluating a field, it's returned immediately after have
+// received it. Errors from this function (not the ones that might
+// be returned from other calls) are WithoutFieldsError,
+// PrivateFieldError and AnonymousFieldError.
+func parseFields(sType reflect.Type) ([]*variableInfo, error) {
+	// Cache to look for repeated variable names
+	parsedNames := make(map[string]string)
 
-		rawVal, err := cp.reader.Get(vName)
-		if err != nil {
-			return errorw.WrapErrorf(
-				ErrorCodeInvalidGetVar, err,
-				"Error while trying to get value from variable %v", vName)
+	fields, err := parseStructFields(sType, "", "", nil, true, parsedNames)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules can reference variables of any level
+	if err := resolveRules(fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// schemas caches the parsed variables per struct type, since they don't
+// depend on the struct values. Invalid structs aren't cached, given that
+// their errors are cheap to obtain again
+var schemas sync.Map // map[reflect.Type][]*variableInfo
+
+// cachedFields returns the parsed variables of sType, parsing
+// them only on the first call. Returns the same errors as
+// parseFields. It's safe to call it concurrently
+func cachedFields(sType reflect.Type) ([]*variableInfo, error) {
+	if fields, ok := schemas.Load(sType); ok {
+		return fields.([]*variableInfo), nil
+	}
+
+	fields, err := parseFields(sType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concurrent calls might have parsed it too
+	cached, _ := schemas.LoadOrStore(sType, fields)
+
+	return cached.([]*variableInfo), nil
+}
+
+// fileSuffix identifies the variable that holds
+// the path of the file with the value of a secret
+const fileSuffix = "_FILE"
+
+// readNamedValue returns the raw value of the variable vName from the
+// config reader and where it was read from, or nil if it's empty. Secrets
+// can be defined instead by vName_FILE, whose value is the path of a file
+// with the secret. Its content is trimmed. Errors are wrapped by
+// errorw.Wrapper with the corresponding code
+func (cp *ConfParser) readNamedValue(vName string, secret bool) (string, *valueOrigin, error) {
+	rawVal, err := cp.reader.Get(vName)
+	if err != nil {
+		return "", nil, errorw.WrapErrorf(
+			ErrorCodeInvalidGetVar, err,
+			"Error while trying to get value from variable %v", vName)
+	}
+
+	origin := &valueOrigin{
+		source:   SourceProvider,
+		readFrom: vName,
+		provider: cp.reader.Describe(vName),
+	}
+
+	if !secret {
+		return rawVal, origin.ifDefined(rawVal), nil
+	}
+
+	fileVarName := vName + fileSuffix
+
+	path, err := cp.reader.Get(fileVarName)
+	if err != nil {
+		return "", nil, errorw.WrapErrorf(
+			ErrorCodeInvalidGetVar, err,
+			"Error while trying to get value from variable %v", fileVarName)
+	}
+
+	if path == "" {
+		return rawVal, origin.ifDefined(rawVal), nil
+	}
+
+	if rawVal != "" {
+		return "", nil, errorw.WrapErrorf(
+			ErrorCodeConflictingVars, nil,
+			"Variable %v can't be defined along with %v", vName, fileVarName)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, errorw.WrapErrorf(
+			ErrorCodeUnreadableFile, err,
+			"Couldn't read file %v of variable %v", path, fileVarName)
+	}
+
+	rawVal = strings.TrimSpace(string(content))
+	origin = &valueOrigin{
+		source:   SourceFile,
+		readFrom: fileVarName,
+		file:     path,
+		provider: cp.reader.Describe(fileVarName),
+	}
+
+	return rawVal, origin.ifDefined(rawVal), nil
+}
+
+// readValue returns the raw value of a variable from the config reader
+// and where it was read from, or nil if it isn't defined. In that case,
+// its aliases are read by the same order, where the first defined one is
+// used and reported as DeprecatedVarWarning. Errors are wrapped by
+// errorw.Wrapper with the corresponding code
+func (cp *ConfParser) readValue(v *variableInfo) (string, *valueOrigin, error) {
+	vName := cp.varName(v.name)
+
+	rawVal, origin, err := cp.readNamedValue(vName, v.secret)
+	if err != nil || origin != nil {
+		return rawVal, origin, err
+	}
+
+	for _, alias := range v.aliases {
+		alias = cp.varName(alias)
+
+		if rawVal, origin, err = cp.readNamedValue(alias, v.secret); err != nil {
+			return "", nil, err
+		} else if origin == nil {
+			continue
 		}
 
-		if rawVal == "" {
-			if v.required {
-				return errorw.WrapErrorf(
-					ErrorCodeMissingVar, nil, "Missing variable %v", vName)
-			}
+		cp.onWarning(&DeprecatedVarWarning{
+			Name:      vName,
+			Alias:     alias,
+			FieldPath: v.fieldPath,
+		})
+
+		origin.deprecated = true
 
-			continue // struct field value isn't changed
+		return rawVal, origin, nil
+	}
+
+	return "", nil, nil
+}
+
+// resolveValue returns the raw value of a variable from the config reader,
+// falling back to its default, and where it came from. ok is false if the
+// field must be left untouched, i.e. the variable isn't defined and doesn't
+// have a default or the raw value is invalid. Errors are wrapped by
+// errorw.Wrapper with the corresponding code
+func (cp *ConfParser) resolveValue(v *variableInfo) (rawVal string, origin *valueOrigin, ok bool, err error) {
+	vName := cp.varName(v.name)
+
+	rawVal, origin, err = cp.readValue(v)
+	if err != nil {
+		return "", &valueOrigin{source: SourceUnset}, false, err
+	}
+
+	if origin == nil {
+		origin = &valueOrigin{source: SourceUnset}
+
+		if v.required {
+			return "", origin, false, errorw.WrapErrorf(
+				ErrorCodeMissingVar, nil, "Missing variable %v", vName)
 		}
 
-		if !v.isValidKeyword(rawVal) {
-			return errorw.WrapErrorf(
-				ErrorCodeUnacceptedVal, nil,
-				"Unaccepted value \"%v\" of variable %v. Valid keywords: %v",
-				rawVal, vName, strings.Join(v.validKeywords(), ", "))
+		if !v.hasDefault {
+			return "", origin, false, nil // struct field value isn't changed
 		}
 
-		if err := v.setValue(v.val, rawVal); err != nil {
-			return errorw.WrapErrorf(
-				ErrorCodeInvalidVarType, err,
-				"Invalid value type of variable %v", vName)
+		rawVal = v.defaultValue
+		origin.source = SourceDefault
+	}
+
+	if keyword, ok := v.unacceptedKeyword(rawVal); ok {
+		return "", origin, false, errorw.WrapErrorf(
+			ErrorCodeUnacceptedVal, nil,
+			"Unaccepted value \"%v\" of variable %v. Valid keywords: %v",
+			keyword, vName, strings.Join(v.validKeywords(), ", "))
+	}
+
+	return rawVal, origin, true, nil
+}
+
+// invalidValue wraps the error returned while converting the raw value of
+// a variable, with ErrorCodeConstraintViolation if it's ConstraintViolationError
+// or ErrorCodeInvalidVarType otherwise
+func (cp *ConfParser) invalidValue(v *variableInfo, err error) error {
+	vName := cp.varName(v.name)
+
+	var violation *ConstraintViolationError
+	if errors.As(err, &violation) {
+		return errorw.WrapErrorf(
+			ErrorCodeConstraintViolation, err,
+			"Invalid value of variable %v", vName)
+	}
+
+	return errorw.WrapErrorf(
+		ErrorCodeInvalidVarType, err,
+		"Invalid value type of variable %v", vName)
+}
+
+// fillValue converts the raw value of the variable v, at position i,
+// and assigns it to its field if it's valid, returning the conversion
+// or constraint error otherwise
+type fillValue = func(i int, v *variableInfo, rawVal string) error
+
+// fillField evaluates the read value of a single variable from the
+// config reader, according to the parsed info. Lastly, fill tries to
+// parse the raw value and set it into the field. Returns where the
+// raw value came from, even if it's invalid. Errors are wrapped by
+// errorw.Wrapper with the corresponding code
+func (cp *ConfParser) fillField(i int, v *variableInfo, fill fillValue) (*valueOrigin, error) {
+	rawVal, origin, ok, err := cp.resolveValue(v)
+	if !ok {
+		return origin, err
+	}
+
+	if err := fill(i, v, rawVal); err != nil {
+		return origin, cp.invalidValue(v, err)
+	}
+
+	return origin, nil
+}
+
+// fillFields fills each field with the value of its variable, set by fill,
+// and checks the rules that depend on other variables, where holds tells if
+// a condition holds, as well as unknown variables in strict mode. Returns
+// where each value came from, even if some is invalid. Instead of stopping
+// on the first invalid variable, collects the errors of all of them and
+// returns VariablesError if there's at least one
+func (cp *ConfParser) fillFields(
+	vars []*variableInfo,
+	fill fillValue,
+	holds func(c *varCondition) bool,
+) ([]*valueOrigin, error) {
+	var errs []error
+
+	defined := make(map[*variableInfo]bool, len(vars))
+	failed := make(map[*variableInfo]bool)
+	origins := make([]*valueOrigin, len(vars))
+
+	for i, v := range vars {
+		origin, err := cp.fillField(i, v, fill)
+
+		defined[v] = origin.isDefined()
+
+		if err != nil {
+			errs = append(errs, err)
+			failed[v] = true
+
+			// The field keeps its previous value
+			origin = &valueOrigin{source: SourceUnset}
 		}
+
+		origin.name = cp.varName(v.name)
+		origins[i] = origin
 	}
 
-	return nil
+	errs = append(errs, cp.checkRules(vars, holds, defined, failed)...)
+	errs = append(errs, cp.checkUnknown(vars)...)
+
+	if len(errs) > 0 {
+		return origins, &VariablesError{errs: errs}
+	}
+
+	return origins, nil
+}
+
+// parseConf fills from like ParseConf, returning where each value came
+// from. Origins are nil if the struct itself is invalid
+func (cp *ConfParser) parseConf(from StructPtr) ([]*valueOrigin, error) {
+	srtVal, err := extractStrVal(from)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := cachedFields(srtVal.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	holds := func(c *varCondition) bool { return c.holds(srtVal) }
+
+	return cp.fillFields(variables, fillReflected(srtVal), holds)
+}
+
+// fillReflected returns the filler of the fields of srtVal, which converts
+// the raw value with the converter of the variable and checks its constraints
+func fillReflected(srtVal *reflect.Value) fillValue {
+	return func(_ int, v *variableInfo, rawVal string) error {
+		// Field is only assigned if the value is valid
+		parsed := reflect.New(v.fieldType).Elem()
+
+		if err := v.setValue(&parsed, rawVal); err != nil {
+			return err
+		}
+
+		if err := v.checkConstraints(parsed, rawVal); err != nil {
+			return err
+		}
+
+		v.fieldOf(srtVal).Set(parsed)
+
+		return nil
+	}
 }
 
 // ParseConf reads the struct contents (field type, tag and its proper name)
@@ -268,26 +885,56 @@ func (cp *ConfParser) fillFields(vars []*variableInfo) error {
 // the resulting value to the corresponding struct field. Errors related to
 // invalid struct pointer or invalid structured fields are returned immediately.
 // In the other hand, errors related to the content returned by the variables
-// reader are wrapped by errorw.Wrapper. Tag element value is trimmed,
-// e.g. name:" VARIABLE_1  "  results in "VARIABLE_1"
+// reader are wrapped by errorw.Wrapper and aggregated in VariablesError, which
+// lists every invalid variable at once. Tag element value is trimmed,
+// e.g. name:" VARIABLE_1  "  results in "VARIABLE_1". If the parser has
+// a namespace (see WithNamespace), it's prepended to every variable. If
+// it's strict (see WithStrict), unknown variables are reported as well.
+// Where each value came from can be inspected afterwards with ConfParser.Explain
 //
 //	Restrictions:
 //
 //	1. Valid types and their conversions:
 //
 //			- string <- directly parsed
-//			- int <- strconv.Atoi(raw)
-//			- int32 <- strconv.ParseInt(raw, 10, 32)
-//			- uint16 <- strconv.ParseUint(raw, 10, 16)
+//			- int <- strconv.ParseInt(raw, 10, 0)
+//			- int8, int16, int32, int64 <- strconv.ParseInt(raw, 10, bits)
+//			- uint, uint8, uint16, uint32, uint64 <- strconv.ParseUint(raw, 10, bits)
+//			- float32, float64 <- strconv.ParseFloat(raw, bits)
 //			- time.Duration <- time.ParseDuration(raw)
 //			- bool <- strconv.ParseBool(raw)
 //			- *utils.Addrs <- utils.ParseAddrs(raw)
+//			- utils.ByteSize <- utils.ParseByteSize(raw), e.g. 64MiB
+//			- url.URL <- url.Parse(raw), which must be absolute
+//			- netip.Addr <- netip.ParseAddr(raw)
+//			- netip.Prefix <- netip.ParsePrefix(raw), e.g. 10.0.0.0/8
+//			- slog.Level <- slog.Level.UnmarshalText(raw), e.g. INFO
+//			- *time.Location <- time.LoadLocation(raw)
+//			- time.Time <- time.Parse(time.RFC3339, raw)
+//			- types registered with RegisterConverter
+//			- T or *T, if *T implements Unmarshaler or
+//			encoding.TextUnmarshaler (the former wins)
+//			- *T, where T is one of the above, is an optional:
+//			it's nil unless the variable (or its default) is
+//			provided, which allows to tell apart an explicit
+//			zero value. Constraints apply to the pointed value
+//			- []T <- each element separated by a comma
+//			is converted to T, where T is one of the above
+//			- map[string]T <- each entry separated by a comma
+//			in the format key=value, where value is converted
+//			to T, being T one of the above
 //
 //	2. Valid tag elements:
 //
 //	    	- name: variable's name (required) in the
 //	     	format `[a-zA-Z]\w+`
 //
+//	    	- aliases: deprecated names, separated by a comma,
+//	    	that are read by the same order when the variable
+//	    	isn't defined. The first defined one is reported as
+//	    	DeprecatedVarWarning. Aliases share the prefix of
+//	    	the variable and must be unique as well
+//
 //	    	- required: valid keywords are true,false,
 //	    	yes and no (field is optional by default)
 //
@@ -295,15 +942,82 @@ func (cp *ConfParser) fillFields(vars []*variableInfo) error {
 //	    	a comma, that can be passed to a given variable
 //	    	(if omitted, it means that are all accepted)
 //
+//	    	- default: value assigned when the variable is
+//	    	empty. It must be convertible to the field type
+//	    	and be one of the accepted keywords (if any).
+//	    	Can't be used along with required
+//
+//	    	- sep: separator of slice elements or map entries
+//	    	(comma by default). Accepted keywords are matched
+//	    	against each element or map entry value
+//
+//	    	- min, max: inclusive bounds of numeric values,
+//	    	including time.Duration, or of types with the method
+//	    	Compare(T) int, e.g. time.Time and netip.Addr. Bounds
+//	    	are converted to the field type (or to each collection
+//	    	element type)
+//
+//	    	- pattern: regular expression that the raw value
+//	    	must match (or each collection element)
+//
+//	    	- len: length of strings (characters) or number of
+//	    	collection elements, in the format N (exact), N..M,
+//	    	N.. or ..M (inclusive)
+//
+//	    	- secret: valid keywords are true,false, yes and no.
+//	    	Secret values are redacted by Dump. By default,
+//	    	variables whose name ends with _SECRET are secrets.
+//	    	A secret NAME can be defined instead by NAME_FILE,
+//	    	which holds the path of a file with the value (its
+//	    	content is trimmed). Both can't be defined at once
+//
+//	    	- reload: valid keywords are true,false, yes and no.
+//	    	Changes of variables that can't be reloaded are
+//	    	ignored by Watcher (reloadable by default)
+//
+//	    	- required_if: conditions in the format NAME=value,
+//	    	separated by a comma. The variable is required if
+//	    	any of them holds. Values are converted to the type
+//	    	of the referenced variable. Can't be used along with
+//	    	required or default
+//
+//	    	- excludes: variables, separated by a comma, that
+//	    	can't be defined along with this one
+//
+//	    	- one_of_group: name of a group where exactly one
+//	    	of its variables (at least two) must be defined.
+//	    	Can't be used along with required
+//
+//	    	Variables referenced by required_if and excludes, as
+//	    	well as group names, are relative to the prefix of the
+//	    	struct that owns the field. Unknown variables are
+//	    	rejected when the struct is parsed
+//
+//	3. Nested structs:
+//
+//	    	Fields of type struct (named or embedded) are
+//	    	parsed recursively. The optional tag element
+//	    	prefix, in the format `[a-zA-Z]\w*`, is prepended
+//	    	to the name of each variable of the nested struct.
+//	    	Variable names must be unique across all levels.
+//	    	The tag element reload applies to all of them
+//
 //	Struct example:
 //
+//	type T struct {
+//		D string `name:"HOST"`
+//	}
+//
 //	type S struct {
 //		A int `name:"VAR_1" accepts:"1,2,  3   "`
 //		B string `name:"    VAR_2" required:"yes   ", accepts:"bark,meow"`
 //		C time.Duration `name:"VAR_3" required:"   false"`
+//		T T `prefix:"VAR_4_"`
+//		E int `name:"VAR_5" default:"10"`
 //	}
 //
-//	// Defined variables: VAR_1=2, VAR_2=bark, VAR_3=1h30m
+//	// Defined variables: VAR_1=2, VAR_2=bark, VAR_3=1h30m, VAR_4_HOST=lo
+//	// VAR_5 is undefined, so E is set to 10
 //
 //	var varReader envvars.VarReader
 //	// varReader setup ...
@@ -313,28 +1027,40 @@ func (cp *ConfParser) fillFields(vars []*variableInfo) error {
 //	_ := parser.ParseConf(s)
 //	fmt.Println(s)
 func (cp *ConfParser) ParseConf(from StructPtr) error {
-	srtVal, err := extractStrVal(from)
-	if err != nil {
-		return err
-	}
-
-	variables, err := parseFields(srtVal)
-	if err != nil {
-		return err
+	origins, err := cp.parseConf(from)
+	if origins != nil {
+		cp.recordOrigins(reflect.TypeOf(from).Elem(), origins)
 	}
 
-	return cp.fillFields(variables)
+	return err
 }
 
-// New returns a new config parser with
-// a variables reader associated to it. Returns
-// MissingVariablesReader if varReader is nil
-func New(varReader *envvars.VarReader) (*ConfParser, error) {
+// New returns a new config parser with a variables reader associated
+// to it. Returns MissingVariablesReader if varReader is nil,
+// InvalidNamespaceError if the namespace is bad formatted or
+// UnsupportedStrictModeError if strict mode is enabled but the
+// variables reader can't list its variables
+func New(varReader *envvars.VarReader, opts ...Option) (*ConfParser, error) {
 	if varReader == nil {
 		return nil, MissingVariablesReader
 	}
 
-	return &ConfParser{
-		reader: varReader,
-	}, nil
+	cp := &ConfParser{
+		reader:    varReader,
+		onWarning: parserLogHandler,
+	}
+
+	for _, opt := range opts {
+		opt(cp)
+	}
+
+	if cp.namespace != "" && !varNameRegex.MatchString(cp.namespace) {
+		return nil, &InvalidNamespaceError{namespace: cp.namespace}
+	}
+
+	if cp.strict && !varReader.Enumerable() {
+		return nil, UnsupportedStrictModeError
+	}
+
+	return cp, nil
 }
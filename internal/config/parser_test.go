@@ -17,13 +17,17 @@ limitations under the License.
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 	"github.com/franciscosbf/micro-dwarf/internal/envvars/providers"
 	"github.com/franciscosbf/micro-dwarf/internal/errorw"
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -106,7 +110,7 @@ func TestValidTypeConverter(t *testing.T) {
 		f := sT.Field(i)
 		fValue := sV.Field(i)
 
-		v := &variableInfo{val: &fValue}
+		v := &variableInfo{fieldType: f.Type}
 
 		if err := selectTypeConverter(v, &f); err != nil {
 			t.Errorf("Unnexpected getting error in field %v: %v", f.Name, err)
@@ -151,9 +155,7 @@ func TestInvalidTypeConverter(t *testing.T) {
 	sT := reflect.TypeOf(s).Elem()
 	f := sT.Field(0)
 
-	fV := reflect.ValueOf(s).Elem().Field(0)
-
-	v := &variableInfo{val: &fV}
+	v := &variableInfo{fieldType: f.Type}
 
 	err := selectTypeConverter(v, &f)
 	if _, ok := err.(*UnsupportedTypeError); !ok {
@@ -178,7 +180,7 @@ func TestValidTagParser(t *testing.T) {
 
 	fakeM := make(map[string]string)
 
-	if err := parseFieldTagKeys(v, &f, fakeM); err != nil {
+	if err := parseFieldTagKeys(v, &f, "", fakeM); err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
@@ -205,7 +207,7 @@ func TestInvalidTagParser(t *testing.T) {
 
 		fakeM := make(map[string]string)
 
-		if err := parseFieldTagKeys(&variableInfo{}, &f, fakeM); err == nil {
+		if err := parseFieldTagKeys(&variableInfo{}, &f, "", fakeM); err == nil {
 			t.Errorf("Expecting getting an error")
 		}
 	}
@@ -230,6 +232,14 @@ func TestInvalidTagParser(t *testing.T) {
 				}{})
 			},
 		},
+		{
+			name: "TestWithRequiredAndDefault",
+			test: func(t *testing.T) {
+				checkError(t, &struct {
+					I int `name:"hi" required:"yes" default:"1"`
+				}{})
+			},
+		},
 		{
 			name: "TestWithInvalidAcceptsFmt",
 			test: func(t *testing.T) {
@@ -238,6 +248,14 @@ func TestInvalidTagParser(t *testing.T) {
 				}{})
 			},
 		},
+		{
+			name: "TestWithInvalidAliasesFmt",
+			test: func(t *testing.T) {
+				checkError(t, &struct {
+					I int `name:"hi" aliases:"hello,1hi"`
+				}{})
+			},
+		},
 	}
 
 	for _, pair := range testBattery {
@@ -258,7 +276,7 @@ func TestRepeatedVarName(t *testing.T) {
 	fakeM := make(map[string]string)
 	fakeM["Joe"] = ""
 
-	err := parseFieldTagKeys(&variableInfo{}, &f, fakeM)
+	err := parseFieldTagKeys(&variableInfo{}, &f, "", fakeM)
 	if _, ok := err.(*RepeatedVarNameError); !ok {
 		t.Errorf("Expecting error RepeatedVarNameError. Instead got: %v", err)
 	}
@@ -273,12 +291,39 @@ func TestInvalidAccepts(t *testing.T) {
 	accepts.Put("1")
 	accepts.Put("str")
 
-	err := validateAccepted(ft, parseInt, accepts)
+	err := validateAccepted("I", ft, parseInt, accepts)
 	if _, ok := err.(*TypeInconsistencyError); !ok {
 		t.Errorf("Expecting getting error TypeInconsistencyError, got %v", err)
 	}
 }
 
+func TestUnnamedTypeErrors(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		from     any
+		expected string
+	}{
+		{"unsupported", &struct {
+			C chan int `name:"C"`
+		}{}, "struct field C contains unsupported type chan int"},
+		{"unsupported elements", &struct {
+			S []chan int `name:"S"`
+		}{}, "struct field S contains unsupported type []chan int"},
+		{"accepts", &struct {
+			S []int `name:"S" accepts:"1,two"`
+		}{}, "struct field S has type int which doesn't match the accepted value two"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			_, err := parseFields(reflect.TypeOf(pair.from).Elem())
+			if err == nil || err.Error() != pair.expected {
+				t.Errorf("Expecting error %v, got %v", pair.expected, err)
+			}
+		})
+	}
+}
+
 func TestValidParseFields(t *testing.T) {
 	type Dummy struct {
 		S string `name:"hello" required:"yes"`
@@ -286,7 +331,7 @@ func TestValidParseFields(t *testing.T) {
 	}
 
 	sV := reflect.ValueOf(&Dummy{}).Elem()
-	vs, err := parseFields(&sV)
+	vs, err := parseFields(sV.Type())
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -319,6 +364,52 @@ func TestValidParseFields(t *testing.T) {
 	checkVar(vs[1], "bye", false, "1", "2")
 }
 
+func TestValidNestedParseFields(t *testing.T) {
+	type Inner struct {
+		S string `name:"HOST"`
+	}
+
+	type Embedded struct {
+		B bool `name:"TLS"`
+	}
+
+	type Dummy struct {
+		Embedded `prefix:"A_"`
+		I        int   `name:"PORT"`
+		N        Inner `prefix:"B_"`
+		M        Inner
+	}
+
+	sV := reflect.ValueOf(&Dummy{}).Elem()
+	vs, err := parseFields(sV.Type())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := []struct{ name, path string }{
+		{"A_TLS", "Embedded.B"},
+		{"PORT", "I"},
+		{"B_HOST", "N.S"},
+		{"HOST", "M.S"},
+	}
+
+	if len(vs) != len(expected) {
+		t.Errorf("Expecting %v parsed variables, got %v", len(expected), len(vs))
+		return
+	}
+
+	for i, e := range expected {
+		if vs[i].name != e.name {
+			t.Errorf("Expecting variable name %v, got %v", e.name, vs[i].name)
+		}
+
+		if vs[i].fieldPath != e.path {
+			t.Errorf("Expecting field path %v, got %v", e.path, vs[i].fieldPath)
+		}
+	}
+}
+
 func TestInvalidParseFields(t *testing.T) {
 	testBattery := []struct {
 		name string
@@ -328,7 +419,7 @@ func TestInvalidParseFields(t *testing.T) {
 			name: "TestEmptyStruct",
 			test: func(t *testing.T) {
 				st := reflect.ValueOf(&struct{}{}).Elem()
-				vs, err := parseFields(&st)
+				vs, err := parseFields(st.Type())
 				if err != WithoutFieldsError {
 					t.Errorf("Expecting error WithoutFieldsError, got: %v", err)
 				}
@@ -342,7 +433,7 @@ func TestInvalidParseFields(t *testing.T) {
 			name: "TestAnonymousField",
 			test: func(t *testing.T) {
 				st := reflect.ValueOf(&struct{ int }{}).Elem()
-				vs, err := parseFields(&st)
+				vs, err := parseFields(st.Type())
 				if err, ok := err.(*AnonymousFieldError); !ok {
 					t.Errorf("Expecting error AnonymousFieldError, got: %v", err)
 				}
@@ -359,7 +450,7 @@ func TestInvalidParseFields(t *testing.T) {
 					I int    `name:"hello"`
 					s string `name:"hi"`
 				}{}).Elem()
-				vs, err := parseFields(&st)
+				vs, err := parseFields(st.Type())
 				if _, ok := err.(*PrivateFieldError); !ok {
 					t.Errorf("Expecting error PrivateFieldError, got: %v", err)
 				}
@@ -369,6 +460,126 @@ func TestInvalidParseFields(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "TestRepeatedNameInNestedStruct",
+			test: func(t *testing.T) {
+				type Inner struct {
+					S string `name:"HOST"`
+				}
+
+				st := reflect.ValueOf(&struct {
+					S string `name:"A_HOST"`
+					N Inner  `prefix:"A_"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*RepeatedVarNameError); !ok {
+					t.Errorf("Expecting error RepeatedVarNameError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestEmptyNestedStruct",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					S string   `name:"hi"`
+					N struct{} `prefix:"A_"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if err != WithoutFieldsError {
+					t.Errorf("Expecting error WithoutFieldsError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestInvalidNestedPrefix",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					N struct {
+						S string `name:"hi"`
+					} `prefix:"_A"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+					t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestDefaultWithInvalidType",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					I int `name:"hi" default:"one"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+					t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestDefaultNotAccepted",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					I int `name:"hi" accepts:"1,2" default:"3"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+					t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestSeparatorInScalar",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					I int `name:"hi" sep:";"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+					t.Errorf("Expecting error InvalidTagKeyValueFmtError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
+		{
+			name: "TestInvalidAcceptedElement",
+			test: func(t *testing.T) {
+				st := reflect.ValueOf(&struct {
+					I []int `name:"hi" accepts:"1,a"`
+				}{}).Elem()
+				vs, err := parseFields(st.Type())
+				if _, ok := err.(*TypeInconsistencyError); !ok {
+					t.Errorf("Expecting error TypeInconsistencyError, got: %v", err)
+				}
+
+				if vs != nil {
+					t.Errorf("Expecting nil slice, got: %v", vs)
+				}
+			},
+		},
 		{
 			name: "TestWithMissingTag",
 			test: func(t *testing.T) {
@@ -376,7 +587,7 @@ func TestInvalidParseFields(t *testing.T) {
 					I int    `name:"joe"`
 					S string `nae:"hi"`
 				}{}).Elem()
-				vs, err := parseFields(&st)
+				vs, err := parseFields(st.Type())
 				if _, ok := err.(*MissingTagKeyError); !ok {
 					t.Errorf("Expecting error MissingTagKeyError, got: %v", err)
 				}
@@ -435,6 +646,106 @@ func TestValidParseConf(t *testing.T) {
 	}
 }
 
+func TestValidNestedParseConf(t *testing.T) {
+	type Tls struct {
+		Enabled bool   `name:"TLS"`
+		Cert    string `name:"TLS_CERT"`
+	}
+
+	type Dummy struct {
+		Host string `name:"NESTED_HOST" required:"yes"`
+		Tls  Tls    `prefix:"NESTED_"`
+	}
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	setVars(map[string]string{
+		"NESTED_HOST":     "localhost",
+		"NESTED_TLS":      "true",
+		"NESTED_TLS_CERT": "cert",
+	})
+	defer unsetVars("NESTED_HOST", "NESTED_TLS", "NESTED_TLS_CERT")
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if d.Host != "localhost" {
+		t.Errorf("Expecting Host field with localhost, got %v", d.Host)
+	}
+	if !d.Tls.Enabled {
+		t.Error("Expecting Tls.Enabled field to be true")
+	}
+	if d.Tls.Cert != "cert" {
+		t.Errorf("Expecting Tls.Cert field with cert, got %v", d.Tls.Cert)
+	}
+}
+
+func TestDefaultParseConf(t *testing.T) {
+	type Dummy struct {
+		I int           `name:"DEFAULT_1" default:"5432"`
+		S string        `name:"DEFAULT_2" accepts:"a,b" default:"b"`
+		T time.Duration `name:"DEFAULT_3" default:"1m"`
+	}
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	setVars(map[string]string{
+		"DEFAULT_3": "1s",
+	})
+	defer unsetVars("DEFAULT_3")
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if d.I != 5432 {
+		t.Errorf("Expecting I field with default 5432, got %v", d.I)
+	}
+	if d.S != "b" {
+		t.Errorf("Expecting S field with default b, got %v", d.S)
+	}
+	if d.T != time.Second {
+		t.Errorf("Expecting T field with 1s, got %v", d.T)
+	}
+}
+
+func TestCollectionParseConf(t *testing.T) {
+	type Dummy struct {
+		O []string          `name:"COLLECTION_1" accepts:"a.com,b.com"`
+		P []int             `name:"COLLECTION_2" sep:";" default:"1;2"`
+		T map[string]string `name:"COLLECTION_3" sep:";"`
+	}
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	setVars(map[string]string{
+		"COLLECTION_1": "a.com, b.com",
+		"COLLECTION_3": "users=1s; friends=2s",
+	})
+	defer unsetVars("COLLECTION_1", "COLLECTION_3")
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if len(d.O) != 2 || d.O[0] != "a.com" || d.O[1] != "b.com" {
+		t.Errorf("Expecting O field with [a.com b.com], got %v", d.O)
+	}
+	if len(d.P) != 2 || d.P[0] != 1 || d.P[1] != 2 {
+		t.Errorf("Expecting P field with default [1 2], got %v", d.P)
+	}
+	if len(d.T) != 2 || d.T["users"] != "1s" || d.T["friends"] != "2s" {
+		t.Errorf("Expecting T field with map[friends:2s users:1s], got %v", d.T)
+	}
+}
+
 func TestInvalidParseConf(t *testing.T) {
 	checkErrorCode := func(t *testing.T, srtPtr any, code errorw.ErrorCode, errName string) {
 		c := envvars.New(&FakeProvider{})
@@ -443,8 +754,12 @@ func TestInvalidParseConf(t *testing.T) {
 		pErr := cp.ParseConf(srtPtr)
 		if pErr == nil {
 			t.Error("Expecting error, got nil")
-		} else if err, ok := pErr.(*errorw.Wrapper); !ok {
-			t.Errorf("Expecting error of type errorw.Wrapper, got %v", err)
+		} else if vErr, ok := pErr.(*VariablesError); !ok {
+			t.Errorf("Expecting error of type VariablesError, got %v", pErr)
+		} else if errs := vErr.Errors(); len(errs) != 1 {
+			t.Errorf("Expecting exactly one aggregated error, got %v", vErr)
+		} else if err, ok := errs[0].(*errorw.Wrapper); !ok {
+			t.Errorf("Expecting error of type errorw.Wrapper, got %v", errs[0])
 		} else {
 			if err.Code() != code {
 				t.Errorf("Expecting error code %v, got %v", errName, err.String())
@@ -512,6 +827,18 @@ func TestInvalidParseConf(t *testing.T) {
 				}{}, ErrorCodeUnacceptedVal, "ErrorCodeUnacceptedVal")
 			},
 		},
+		{
+			name: "TestInvalidCollectionKeyword",
+			test: func(t *testing.T) {
+				setVars(map[string]string{
+					"aa": "hello,hi",
+				})
+				defer unsetVars("aa")
+				checkErrorCode(t, &struct {
+					I []string `name:"aa" accepts:"hello,bye"`
+				}{}, ErrorCodeUnacceptedVal, "ErrorCodeUnacceptedVal")
+			},
+		},
 		{
 			name: "TestInvalidType",
 			test: func(t *testing.T) {
@@ -530,3 +857,622 @@ func TestInvalidParseConf(t *testing.T) {
 		t.Run(pair.name, pair.test)
 	}
 }
+
+func TestAggregatedParseConf(t *testing.T) {
+	type Dummy struct {
+		A string        `name:"AGGREGATED_1" required:"yes"`
+		B string        `name:"AGGREGATED_2" accepts:"hello,bye"`
+		C int           `name:"AGGREGATED_3"`
+		D time.Duration `name:"AGGREGATED_4" required:"yes"`
+		E string        `name:"AGGREGATED_5"`
+	}
+
+	setVars(map[string]string{
+		"AGGREGATED_2": "hi",
+		"AGGREGATED_3": "one",
+		"AGGREGATED_5": "fine",
+	})
+	defer unsetVars("AGGREGATED_2", "AGGREGATED_3", "AGGREGATED_5")
+
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	d := &Dummy{}
+	pErr := cp.ParseConf(d)
+
+	vErr, ok := pErr.(*VariablesError)
+	if !ok {
+		t.Errorf("Expecting error of type VariablesError, got %v", pErr)
+		return
+	}
+
+	expected := []errorw.ErrorCode{
+		ErrorCodeMissingVar,
+		ErrorCodeUnacceptedVal,
+		ErrorCodeInvalidVarType,
+		ErrorCodeMissingVar,
+	}
+
+	errs := vErr.Errors()
+	if len(errs) != len(expected) {
+		t.Errorf("Expecting %v aggregated errors, got %v", len(expected), vErr)
+		return
+	}
+
+	for i, code := range expected {
+		if err, ok := errs[i].(*errorw.Wrapper); !ok || err.Code() != code {
+			t.Errorf("Expecting error with code %v at position %v, got %v", code, i, errs[i])
+		}
+	}
+
+	var wErr *errorw.Wrapper
+	if !errors.As(pErr, &wErr) {
+		t.Error("Expecting errors.As to find an errorw.Wrapper")
+	}
+
+	if d.E != "fine" {
+		t.Errorf("Expecting valid variables to be assigned, got %v", d.E)
+	}
+}
+
+func TestOptionalParseConf(t *testing.T) {
+	type Dummy struct {
+		A *int           `name:"OPTIONAL_1"`
+		B *time.Duration `name:"OPTIONAL_2" default:"1s"`
+		C *float64       `name:"OPTIONAL_3" min:"0" max:"1"`
+		D *string        `name:"OPTIONAL_4"`
+	}
+
+	setVars(map[string]string{"OPTIONAL_1": "0", "OPTIONAL_3": "0.5"})
+	defer unsetVars("OPTIONAL_1", "OPTIONAL_3")
+
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if d.A == nil || *d.A != 0 {
+		t.Errorf("Expecting explicit zero, got %v", d.A)
+	}
+
+	if d.B == nil || *d.B != time.Second {
+		t.Errorf("Expecting default value, got %v", d.B)
+	}
+
+	if d.C == nil || *d.C != 0.5 {
+		t.Errorf("Expecting 0.5, got %v", d.C)
+	}
+
+	if d.D != nil {
+		t.Errorf("Expecting nil, got %v", *d.D)
+	}
+
+	setVars(map[string]string{"OPTIONAL_3": "1.5"})
+
+	var vErr *VariablesError
+	if err := cp.ParseConf(&Dummy{}); !errors.As(err, &vErr) {
+		t.Errorf("Expecting error VariablesError, got %v", err)
+	}
+}
+
+func TestSecretFileParseConf(t *testing.T) {
+	type Dummy struct {
+		A string `name:"SECRET_FILE_1_SECRET" required:"yes"`
+		B string `name:"SECRET_FILE_2" secret:"yes"`
+		C string `name:"SECRET_FILE_3"`
+	}
+
+	dir := t.TempDir()
+
+	secretPath := dir + "/secret"
+	if err := os.WriteFile(secretPath, []byte("  pem\ncontent \n\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	vars := []string{
+		"SECRET_FILE_1_SECRET", "SECRET_FILE_1_SECRET_FILE",
+		"SECRET_FILE_2", "SECRET_FILE_2_FILE", "SECRET_FILE_3_FILE"}
+
+	testBattery := []struct {
+		name     string
+		vars     map[string]string
+		expected []errorw.ErrorCode
+	}{
+		{
+			name: "TestValid",
+			vars: map[string]string{
+				"SECRET_FILE_1_SECRET_FILE": secretPath,
+				"SECRET_FILE_2":             "value",
+				"SECRET_FILE_3_FILE":        secretPath,
+			},
+		},
+		{
+			name: "TestBothDefined",
+			vars: map[string]string{
+				"SECRET_FILE_1_SECRET":      "value",
+				"SECRET_FILE_1_SECRET_FILE": secretPath,
+			},
+			expected: []errorw.ErrorCode{ErrorCodeConflictingVars},
+		},
+		{
+			name: "TestUnreadableFile",
+			vars: map[string]string{
+				"SECRET_FILE_1_SECRET": "value",
+				"SECRET_FILE_2_FILE":   dir + "/missing",
+			},
+			expected: []errorw.ErrorCode{ErrorCodeUnreadableFile},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			setVars(pair.vars)
+			defer unsetVars(vars...)
+
+			cp, _ := New(envvars.New(&FakeProvider{}))
+
+			d := &Dummy{}
+			pErr := cp.ParseConf(d)
+
+			if pair.expected == nil {
+				if pErr != nil {
+					t.Errorf("Unexpected error: %v", pErr)
+				}
+
+				if d.A != "pem\ncontent" || d.B != "value" || d.C != "" {
+					t.Errorf("Unexpected values: %+v", d)
+				}
+
+				return
+			}
+
+			vErr, ok := pErr.(*VariablesError)
+			if !ok {
+				t.Errorf("Expecting error of type VariablesError, got %v", pErr)
+				return
+			}
+
+			errs := vErr.Errors()
+			if len(errs) != len(pair.expected) {
+				t.Errorf("Expecting %v aggregated errors, got %v", len(pair.expected), vErr)
+				return
+			}
+
+			for i, code := range pair.expected {
+				if err, ok := errs[i].(*errorw.Wrapper); !ok || err.Code() != code {
+					t.Errorf("Expecting error with code %v at position %v, got %v", code, i, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRepeatedSecretFileVarName(t *testing.T) {
+	st := reflect.ValueOf(&struct {
+		A string `name:"A_SECRET"`
+		B string `name:"A_SECRET_FILE"`
+	}{}).Elem()
+
+	if _, err := parseFields(st.Type()); err == nil {
+		t.Error("Expecting error RepeatedVarNameError, got nil")
+	} else if _, ok := err.(*RepeatedVarNameError); !ok {
+		t.Errorf("Expecting error RepeatedVarNameError, got %v", err)
+	}
+}
+
+func TestCachedSchema(t *testing.T) {
+	type Dummy struct {
+		A string `name:"CACHED_1"`
+	}
+
+	sT := reflect.TypeOf(Dummy{})
+
+	first, err := cachedFields(sT)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second, _ := cachedFields(sT)
+	if &first[0] != &second[0] {
+		t.Error("Expecting the same cached variables on the second call")
+	}
+
+	type Invalid struct {
+		a string `name:"CACHED_2"`
+	}
+
+	if _, err := cachedFields(reflect.TypeOf(Invalid{})); err == nil {
+		t.Error("Expecting error PrivateFieldError, got nil")
+	}
+	if _, ok := schemas.Load(reflect.TypeOf(Invalid{})); ok {
+		t.Error("Expecting invalid struct to not be cached")
+	}
+}
+
+func TestConcurrentParseConf(t *testing.T) {
+	type Dummy struct {
+		I int               `name:"CONCURRENT_1" required:"yes"`
+		S []string          `name:"CONCURRENT_2" accepts:"a,b"`
+		M map[string]string `name:"CONCURRENT_3"`
+	}
+
+	setVars(map[string]string{
+		"CONCURRENT_1": "1",
+		"CONCURRENT_2": "a,b",
+		"CONCURRENT_3": "k=v",
+	})
+	defer unsetVars("CONCURRENT_1", "CONCURRENT_2", "CONCURRENT_3")
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			d := &Dummy{}
+			if err := cp.ParseConf(d); err != nil {
+				t.Errorf("Unexpected error from config parser: %v", err)
+				return
+			}
+
+			if d.I != 1 || len(d.S) != 2 || d.M["k"] != "v" {
+				t.Errorf("Unexpected parsed values: %+v", d)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+type benchConf struct {
+	Host     string        `name:"BENCH_HOST" default:"localhost"`
+	Port     uint16        `name:"BENCH_PORT" default:"5432" min:"1"`
+	User     string        `name:"BENCH_USER" default:"user" len:"1.."`
+	Mode     string        `name:"BENCH_MODE" accepts:"a,b,c" default:"b"`
+	Timeout  time.Duration `name:"BENCH_TIMEOUT" default:"1s"`
+	Hosts    []string      `name:"BENCH_HOSTS" default:"a,b,c"`
+	Tls      benchTlsConf  `prefix:"BENCH_"`
+	Replicas *int          `name:"BENCH_REPLICAS"`
+}
+
+type benchTlsConf struct {
+	Enabled bool   `name:"TLS" default:"false"`
+	Cert    string `name:"TLS_CERT" required_if:"TLS=true"`
+}
+
+func BenchmarkParseConf(b *testing.B) {
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	for i := 0; i < b.N; i++ {
+		if err := cp.ParseConf(&benchConf{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseConfUncached(b *testing.B) {
+	cp, _ := New(envvars.New(&FakeProvider{}))
+	sT := reflect.TypeOf(benchConf{})
+
+	for i := 0; i < b.N; i++ {
+		schemas.Delete(sT)
+
+		if err := cp.ParseConf(&benchConf{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParallelParseConf(b *testing.B) {
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := cp.ParseConf(&benchConf{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestRepeatedAliasVarName(t *testing.T) {
+	testBattery := []struct {
+		name string
+		st   any
+	}{
+		{
+			name: "TestAliasOfAnotherVar",
+			st: &struct {
+				A string `name:"ALIAS_A"`
+				B string `name:"ALIAS_B" aliases:"ALIAS_A"`
+			}{},
+		},
+		{
+			name: "TestVarWithNameOfAlias",
+			st: &struct {
+				A string `name:"ALIAS_A" aliases:"ALIAS_B"`
+				B string `name:"ALIAS_B"`
+			}{},
+		},
+		{
+			name: "TestAliasOfItself",
+			st: &struct {
+				A string `name:"ALIAS_A" aliases:"ALIAS_A"`
+			}{},
+		},
+		{
+			name: "TestSecretAliasFile",
+			st: &struct {
+				A string `name:"ALIAS_A_SECRET" aliases:"ALIAS_B"`
+				B string `name:"ALIAS_B_FILE"`
+			}{},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			sT := reflect.TypeOf(pair.st).Elem()
+
+			if _, err := parseFields(sT); err == nil {
+				t.Error("Expecting error RepeatedVarNameError, got nil")
+			} else if _, ok := err.(*RepeatedVarNameError); !ok {
+				t.Errorf("Expecting error RepeatedVarNameError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAliasParseConf(t *testing.T) {
+	type Nested struct {
+		S string `name:"SECRET" aliases:"OLD_SECRET"`
+	}
+
+	type Dummy struct {
+		A string `name:"ALIAS_NEW" aliases:"ALIAS_OLD,ALIAS_OLDER"`
+		N Nested `prefix:"ALIAS_"`
+	}
+
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretPath, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testBattery := []struct {
+		name     string
+		vars     map[string]string
+		expected Dummy
+		warnings []string
+	}{
+		{
+			name:     "TestPrimaryNameWins",
+			vars:     map[string]string{"ALIAS_NEW": "new", "ALIAS_OLD": "old"},
+			expected: Dummy{A: "new"},
+		},
+		{
+			name:     "TestFirstDefinedAlias",
+			vars:     map[string]string{"ALIAS_OLDER": "older"},
+			expected: Dummy{A: "older"},
+			warnings: []string{"ALIAS_OLDER"},
+		},
+		{
+			name:     "TestAliasesOrder",
+			vars:     map[string]string{"ALIAS_OLD": "old", "ALIAS_OLDER": "older"},
+			expected: Dummy{A: "old"},
+			warnings: []string{"ALIAS_OLD"},
+		},
+		{
+			name:     "TestPrefixedSecretAliasFile",
+			vars:     map[string]string{"ALIAS_OLD_SECRET_FILE": secretPath},
+			expected: Dummy{N: Nested{S: "s3cret"}},
+			warnings: []string{"ALIAS_OLD_SECRET"},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			for name, value := range pair.vars {
+				t.Setenv(name, value)
+			}
+
+			var warnings []string
+
+			handler := func(err error) {
+				warning, ok := err.(*DeprecatedVarWarning)
+				if !ok {
+					t.Errorf("Expecting warning DeprecatedVarWarning, got %v", err)
+					return
+				}
+
+				warnings = append(warnings, warning.Alias)
+			}
+
+			cp, _ := New(envvars.New(&FakeProvider{}), WithWarningHandler(handler))
+
+			d := &Dummy{}
+			if err := cp.ParseConf(d); err != nil {
+				t.Errorf("Unexpected error from config parser: %v", err)
+				return
+			}
+
+			if *d != pair.expected {
+				t.Errorf("Expecting %+v, got %+v", pair.expected, *d)
+			}
+			if !reflect.DeepEqual(warnings, pair.warnings) {
+				t.Errorf("Expecting warnings of %v, got %v", pair.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestNamespaceParseConf(t *testing.T) {
+	type Dummy struct {
+		Host  string `name:"HOST" required:"yes"`
+		Port  int    `name:"PORT" aliases:"PORT_NUMBER"`
+		Token string `name:"TOKEN_SECRET" required_if:"PORT=80"`
+	}
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("token"), 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Setenv("HOST", "unused")
+	t.Setenv("USERS_HOST", "localhost")
+	t.Setenv("USERS_PORT_NUMBER", "80")
+	t.Setenv("USERS_TOKEN_SECRET_FILE", tokenPath)
+
+	var warnings []string
+
+	cp, err := New(envvars.New(&FakeProvider{}),
+		WithNamespace("USERS"),
+		WithWarningHandler(func(err error) {
+			warnings = append(warnings, err.(*DeprecatedVarWarning).Alias)
+		}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if d.Host != "localhost" || d.Port != 80 || d.Token != "token" {
+		t.Errorf("Unexpected parsed values: %+v", d)
+	}
+	if !reflect.DeepEqual(warnings, []string{"USERS_PORT_NUMBER"}) {
+		t.Errorf("Expecting warning of USERS_PORT_NUMBER, got %v", warnings)
+	}
+
+	// Errors refer to the namespaced names
+	t.Setenv("USERS_HOST", "")
+	t.Setenv("USERS_TOKEN_SECRET_FILE", "")
+
+	err = cp.ParseConf(&Dummy{})
+	for _, name := range []string{"USERS_HOST", "USERS_TOKEN_SECRET, required when USERS_PORT=80"} {
+		if err == nil || !strings.Contains(err.Error(), "Missing variable "+name) {
+			t.Errorf("Expecting missing variable %v, got %v", name, err)
+		}
+	}
+}
+
+func TestInvalidNamespace(t *testing.T) {
+	for _, namespace := range []string{"1USERS", "USERS-DB", "_"} {
+		_, err := New(envvars.New(&FakeProvider{}), WithNamespace(namespace))
+		if _, ok := err.(*InvalidNamespaceError); !ok {
+			t.Errorf("Expecting error InvalidNamespaceError with %v, got %v", namespace, err)
+		}
+	}
+}
+
+func TestStrictParseConf(t *testing.T) {
+	type Tls struct {
+		Enabled bool   `name:"TLS"`
+		Cert    string `name:"TLS_CERT_SECRET"`
+	}
+
+	type Dummy struct {
+		Host     string `name:"STRICT_HOST"`
+		MaxConns int    `name:"STRICT_POOL_MAX_CONS" aliases:"STRICT_POOL_MAX"`
+		Name     string `name:"NAME"`
+		Tls      Tls    `prefix:"STRICT_"`
+	}
+
+	testBattery := []struct {
+		name      string
+		namespace string
+		vars      map[string]string
+		expected  []string
+	}{
+		{
+			name: "TestWithoutUnknownVars",
+			vars: map[string]string{
+				"STRICT_HOST":                 "localhost",
+				"STRICT_POOL_MAX":             "1",
+				"STRICT_TLS_CERT_SECRET_FILE": "/dev/null",
+				"NAME_UNRELATED":              "ignored",
+			},
+		},
+		{
+			name: "TestUnknownVars",
+			vars: map[string]string{
+				"STRICT_HOST":           "localhost",
+				"STRICT_POOL_MAX_CONNS": "1",
+				"STRICT_TSL":            "true",
+				"STRICT_SOMETHING_ELSE": "value",
+			},
+			expected: []string{
+				"Unknown variable STRICT_POOL_MAX_CONNS, did you mean STRICT_POOL_MAX_CONS?",
+				"Unknown variable STRICT_SOMETHING_ELSE",
+				"Unknown variable STRICT_TSL, did you mean STRICT_TLS?",
+			},
+		},
+		{
+			name:      "TestNamespacedUnknownVars",
+			namespace: "USERS",
+			vars: map[string]string{
+				"USERS_STRICT_HOTS": "localhost",
+				"STRICT_UNKNOWN":    "ignored",
+			},
+			expected: []string{
+				"Unknown variable USERS_STRICT_HOTS, did you mean USERS_STRICT_HOST?",
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			for name, value := range pair.vars {
+				t.Setenv(name, value)
+			}
+
+			cp, err := New(envvars.New(providers.NewEnvVariables()),
+				WithStrict(), WithNamespace(pair.namespace), WithWarningHandler(nil))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = cp.ParseConf(&Dummy{})
+
+			if pair.expected == nil {
+				if err != nil {
+					t.Errorf("Unexpected error from config parser: %v", err)
+				}
+
+				return
+			}
+
+			vErr, ok := err.(*VariablesError)
+			if !ok {
+				t.Errorf("Expecting error of type VariablesError, got %v", err)
+				return
+			}
+
+			var msgs []string
+			for _, err := range vErr.Errors() {
+				if errw, ok := err.(*errorw.Wrapper); !ok || errw.Code() != ErrorCodeUnknownVar {
+					t.Errorf("Expecting error with code ErrorCodeUnknownVar, got %v", err)
+				}
+
+				msgs = append(msgs, err.Error())
+			}
+
+			if !reflect.DeepEqual(msgs, pair.expected) {
+				t.Errorf("Expecting errors %v, got %v", pair.expected, msgs)
+			}
+		})
+	}
+}
+
+func TestUnsupportedStrictMode(t *testing.T) {
+	_, err := New(envvars.New(&FakeProvider{}), WithStrict())
+	if err != UnsupportedStrictModeError {
+		t.Errorf("Expecting error UnsupportedStrictModeError, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Source tells where the value of a variable came from
+type Source int
+
+// Value sources
+const (
+	// SourceUnset means that the field wasn't assigned
+	SourceUnset Source = iota
+	// SourceProvider means that the value was read from the provider
+	SourceProvider
+	// SourceFile means that the value was read from the file
+	// whose path is defined by the variable NAME_FILE
+	SourceFile
+	// SourceDefault means that the value came from the tag key default
+	SourceDefault
+)
+
+var sourceNames = [...]string{"unset", "provider", "file", "default"}
+
+func (s Source) String() string {
+	if s < 0 || int(s) >= len(sourceNames) {
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+
+	return sourceNames[s]
+}
+
+// MarshalText allows to encode sources by their names, e.g. in JSON
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Provenance describes where the value of a variable came from
+type Provenance struct {
+	Name       string // Variable's name, including the namespace (if any)
+	FieldPath  string // Struct field path, e.g. Tls.Cert
+	Value      string // Current value, formatted like in Dump, i.e. secrets are redacted
+	Source     Source // Where the value came from
+	ReadFrom   string // Variable that defines the value, or NAME_FILE if read from a file
+	Deprecated bool   // Tells if ReadFrom is an alias of the variable
+	File       string // Path of the file, if Source is SourceFile
+	Provider   string // Provider that supplied ReadFrom, see envvars.VarReader.Describe
+}
+
+// String returns the provenance in the format NAME=value (origin)
+func (p *Provenance) String() string {
+	var origin string
+
+	switch p.Source {
+	case SourceProvider, SourceFile:
+		readFrom := p.ReadFrom
+		if p.Deprecated {
+			readFrom = "deprecated " + readFrom
+		}
+
+		origin = fmt.Sprintf("from %v in %v", readFrom, p.Provider)
+		if p.Source == SourceFile {
+			origin = fmt.Sprintf("from file %v, referenced by %v in %v", p.File, readFrom, p.Provider)
+		}
+	default:
+		origin = p.Source.String()
+	}
+
+	return fmt.Sprintf("%v=%v (%v)", p.Name, p.Value, origin)
+}
+
+// valueOrigin describes where the raw value of a variable was read from
+type valueOrigin struct {
+	name       string
+	source     Source
+	readFrom   string
+	deprecated bool
+	file       string
+	provider   string
+}
+
+// ifDefined returns o if rawVal isn't empty, otherwise nil
+func (o *valueOrigin) ifDefined(rawVal string) *valueOrigin {
+	if rawVal == "" {
+		return nil
+	}
+
+	return o
+}
+
+// isDefined tells if the value was read from the config reader
+func (o *valueOrigin) isDefined() bool {
+	return o.source == SourceProvider || o.source == SourceFile
+}
+
+// recordOrigins replaces the origins of the variables of
+// the last parsed struct of type sType, see ConfParser.Explain
+func (cp *ConfParser) recordOrigins(sType reflect.Type, origins []*valueOrigin) {
+	cp.origins.Store(sType, origins)
+}
+
+// explain describes the value of each variable of srtVal,
+// along with the given origins, by the same order of variables
+func explain(srtVal *reflect.Value, variables []*variableInfo, origins []*valueOrigin) []*Provenance {
+	table := make([]*Provenance, len(variables))
+
+	for i, v := range variables {
+		origin := origins[i]
+
+		table[i] = &Provenance{
+			Name:       origin.name,
+			FieldPath:  v.fieldPath,
+			Value:      dumpValue(v, srtVal),
+			Source:     origin.source,
+			ReadFrom:   origin.readFrom,
+			Deprecated: origin.deprecated,
+			File:       origin.file,
+			Provider:   origin.provider,
+		}
+	}
+
+	return table
+}
+
+// Explain describes where the value of each variable of a config struct
+// came from, by the same order of the struct fields. Origins are the ones
+// of the last struct of the same type filled by cp, either by ParseConf or
+// by ParseGenerated, so copies of it can be explained too. If cp hasn't
+// filled a struct of that type, returns NotParsedError. Secrets are redacted. Returns the same
+// struct related errors as Dump
+func (cp *ConfParser) Explain(from StructPtr) ([]*Provenance, error) {
+	srtVal, err := extractStrVal(from)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := cachedFields(srtVal.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	origins, ok := cp.origins.Load(srtVal.Type())
+	if !ok {
+		return nil, NotParsedError
+	}
+
+	return explain(srtVal, variables, origins.([]*valueOrigin)), nil
+}
+
+// explainText renders each provenance of table in a line
+func explainText(table []*Provenance) string {
+	var b strings.Builder
+	for _, p := range table {
+		b.WriteString(p.String())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// ExplainText returns the provenance of each variable of a config
+// struct in a line, see ConfParser.Explain and Provenance.String
+func (cp *ConfParser) ExplainText(from StructPtr) (string, error) {
+	table, err := cp.Explain(from)
+	if err != nil {
+		return "", err
+	}
+
+	return explainText(table), nil
+}
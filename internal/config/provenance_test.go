@@ -0,0 +1,131 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars/providers"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type provenanceDummy struct {
+	Host     string `name:"PROV_HOST"`
+	Port     int    `name:"PROV_PORT" aliases:"PROV_PORT_NUMBER"`
+	Password string `name:"PROV_PASSWORD_SECRET"`
+	Mode     string `name:"PROV_MODE" default:"fast"`
+	Name     string `name:"PROV_NAME" reload:"no"`
+}
+
+func TestExplain(t *testing.T) {
+	passwordPath := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordPath, []byte("pass"), 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Setenv("USERS_PROV_HOST", "localhost")
+	t.Setenv("USERS_PROV_PORT_NUMBER", "80")
+	t.Setenv("USERS_PROV_PASSWORD_SECRET_FILE", passwordPath)
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()),
+		WithNamespace("USERS"), WithWarningHandler(nil))
+
+	d := &provenanceDummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Fatalf("Unexpected error from config parser: %v", err)
+	}
+
+	explained, err := cp.ExplainText(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"USERS_PROV_HOST=localhost (from USERS_PROV_HOST in env)",
+		"USERS_PROV_PORT=80 (from deprecated USERS_PROV_PORT_NUMBER in env)",
+		"USERS_PROV_PASSWORD_SECRET=" + RedactedValue + " (from file " + passwordPath +
+			", referenced by USERS_PROV_PASSWORD_SECRET_FILE in env)",
+		"USERS_PROV_MODE=fast (default)",
+		"USERS_PROV_NAME= (unset)",
+	}, "\n") + "\n"
+
+	if explained != expected {
+		t.Errorf("Expecting explanation:\n%v\ngot:\n%v", expected, explained)
+	}
+
+	table, _ := cp.Explain(d)
+
+	copied := *d
+	if copiedTable, err := cp.Explain(&copied); err != nil {
+		t.Errorf("Unexpected error explaining a copy: %v", err)
+	} else if !reflect.DeepEqual(copiedTable, table) {
+		t.Errorf("Expecting copy explained like the original, got %v", copiedTable)
+	}
+
+	encoded, err := json.Marshal(table[3])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"Source":"default"`) {
+		t.Errorf("Expecting source encoded by its name, got %s", encoded)
+	}
+}
+
+func TestExplainNotParsed(t *testing.T) {
+	cp, _ := New(envvars.New(&FakeProvider{}))
+
+	if _, err := cp.Explain(&provenanceDummy{}); err != NotParsedError {
+		t.Errorf("Expecting error NotParsedError, got %v", err)
+	}
+
+	if _, err := cp.Explain(provenanceDummy{}); err != InvalidPointerError {
+		t.Errorf("Expecting error InvalidPointerError, got %v", err)
+	}
+}
+
+func TestExplainReloaded(t *testing.T) {
+	cp, _ := New(envvars.New(&FakeProvider{}), WithWarningHandler(nil))
+
+	t.Setenv("PROV_NAME", "first")
+
+	watcher, err := NewWatcher[provenanceDummy](cp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	watcher.OnWarning(nil)
+
+	t.Setenv("PROV_NAME", "")
+	t.Setenv("PROV_MODE", "slow")
+
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	table := watcher.Explain()
+
+	name, mode := table[4], table[3]
+	if name.Value != "first" || name.Source != SourceProvider {
+		t.Errorf("Expecting reverted name with its previous provenance, got %v", name)
+	}
+	if mode.Value != "slow" || mode.Source != SourceProvider {
+		t.Errorf("Expecting reloaded mode from the provider, got %v", mode)
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// VariableReference describes a variable of a config struct
+type VariableReference struct {
+	// Name is the variable's name, including prefixes
+	Name string
+	// Aliases contains the deprecated names, including prefixes
+	Aliases []string
+	// FieldPath is the field location, e.g. Tls.Cert
+	FieldPath string
+	// Type is the Go type of the field
+	Type string
+	// Required tells if the variable must be defined
+	Required bool
+	// Secret tells if the variable holds a secret
+	Secret bool
+	// Accepts contains the accepted keywords, sorted
+	Accepts []string
+	// Default contains the default value, if HasDefault
+	Default    string
+	HasDefault bool
+	// Constraints contains the validation tags, e.g. min=1
+	Constraints []string
+	// Doc is the doc comment of the field, if any
+	Doc string
+}
+
+// FieldDocFunc returns the doc comment of
+// the field fieldName declared in owner
+type FieldDocFunc func(owner reflect.Type, fieldName string) string
+
+// ReferenceSection groups the variables of a config struct
+type ReferenceSection struct {
+	Title     string
+	Variables []*VariableReference
+}
+
+// rulesOf returns the rules of a variable in the format key=value,
+// where referenced variables are shown with their full name
+func rulesOf(v *variableInfo) []string {
+	r := v.rules
+	if r == nil {
+		return nil
+	}
+
+	var rules []string
+
+	if r.requiredIf != nil {
+		conditions := make([]string, len(r.requiredIf))
+		for i, condition := range r.requiredIf {
+			conditions[i] = condition.name + conditionSeparator + condition.rawValue
+		}
+		rules = append(rules, fmt.Sprintf(
+			"%v=%v", requiredIfTagKey, strings.Join(conditions, ",")))
+	}
+	if r.excludes != nil {
+		rules = append(rules, fmt.Sprintf(
+			"%v=%v", excludesTagKey, strings.Join(r.excludes, ",")))
+	}
+	if r.group != "" {
+		rules = append(rules, fmt.Sprintf("%v=%v", oneOfGroupTagKey, r.group))
+	}
+
+	return rules
+}
+
+// constraintsOf returns each constraint in the tag format
+func constraintsOf(v *variableInfo) []string {
+	constraints := rulesOf(v)
+
+	c := v.constraints
+	if c == nil {
+		return constraints
+	}
+
+	if c.min != nil {
+		constraints = append(constraints, fmt.Sprintf("%v=%v", minTagKey, c.rawMin))
+	}
+	if c.max != nil {
+		constraints = append(constraints, fmt.Sprintf("%v=%v", maxTagKey, c.rawMax))
+	}
+	if c.pattern != nil {
+		constraints = append(constraints, fmt.Sprintf("%v=%v", patternTagKey, c.pattern))
+	}
+	if c.hasLen {
+		constraints = append(constraints, fmt.Sprintf("%v=%v", lenTagKey, c.rawLen()))
+	}
+
+	return constraints
+}
+
+// Reference parses a config struct and describes each one of its variables,
+// by the same order of the struct fields. If docOf isn't nil, it's used to
+// fetch the doc comment of each field. Returns the same struct related
+// errors as ConfParser.ParseConf
+func Reference(from StructPtr, docOf FieldDocFunc) ([]*VariableReference, error) {
+	srtVal, err := extractStrVal(from)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := cachedFields(srtVal.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*VariableReference, len(variables))
+
+	for i, v := range variables {
+		accepts := v.validKeywords()
+		sort.Strings(accepts)
+
+		ref := &VariableReference{
+			Name:        v.name,
+			Aliases:     v.aliases,
+			FieldPath:   v.fieldPath,
+			Type:        v.fieldType.String(),
+			Required:    v.required,
+			Secret:      v.secret,
+			Accepts:     accepts,
+			Default:     v.defaultValue,
+			HasDefault:  v.hasDefault,
+			Constraints: constraintsOf(v),
+		}
+
+		if docOf != nil {
+			ref.Doc = docOf(v.owner, v.fieldName)
+		}
+
+		refs[i] = ref
+	}
+
+	return refs, nil
+}
+
+// NewReferenceSection returns the reference of a config struct under a title
+func NewReferenceSection(title string, from StructPtr, docOf FieldDocFunc) (*ReferenceSection, error) {
+	refs, err := Reference(from, docOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReferenceSection{Title: title, Variables: refs}, nil
+}
+
+// markdownCell escapes a table cell content
+func markdownCell(content string) string {
+	content = strings.ReplaceAll(content, "|", "\\|")
+
+	return strings.ReplaceAll(content, "\n", " ")
+}
+
+// markdownCode formats each value as inline code
+func markdownCode(values []string) string {
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = fmt.Sprintf("`%v`", v)
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// WriteMarkdownReference writes a Markdown document
+// with a table of variables per section
+func WriteMarkdownReference(w io.Writer, sections ...*ReferenceSection) error {
+	var b strings.Builder
+
+	b.WriteString("# Configuration reference\n")
+
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n## %v\n\n", section.Title)
+		b.WriteString("| Variable | Type | Required | Secret | Accepts | Default | Constraints | Description |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|\n")
+
+		for _, ref := range section.Variables {
+			required, secret := "no", "no"
+			if ref.Required {
+				required = "yes"
+			}
+			if ref.Secret {
+				secret = "yes"
+			}
+
+			defaultVal := ""
+			if ref.HasDefault {
+				defaultVal = markdownCode([]string{ref.Default})
+			}
+
+			variable := markdownCode([]string{ref.Name})
+			if len(ref.Aliases) > 0 {
+				variable += fmt.Sprintf(" (deprecated: %v)", markdownCode(ref.Aliases))
+			}
+
+			fmt.Fprintf(&b, "| %v | `%v` | %v | %v | %v | %v | %v | %v |\n",
+				variable, ref.Type, required, secret,
+				markdownCell(markdownCode(ref.Accepts)),
+				markdownCell(defaultVal),
+				markdownCell(markdownCode(ref.Constraints)),
+				markdownCell(ref.Doc))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// WriteEnvExample writes a .env file example, where each variable
+// is preceded by its description. Optional variables are commented
+// out, with the default value (if any) assigned to them
+func WriteEnvExample(w io.Writer, sections ...*ReferenceSection) error {
+	var b strings.Builder
+
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "# %v\n", section.Title)
+
+		for _, ref := range section.Variables {
+			b.WriteString("\n")
+
+			for _, line := range strings.Split(ref.Doc, "\n") {
+				if line != "" {
+					fmt.Fprintf(&b, "# %v\n", line)
+				}
+			}
+
+			details := []string{ref.Type}
+			if ref.Required {
+				details = append(details, "required")
+			}
+			if ref.Secret {
+				details = append(details,
+					fmt.Sprintf("secret, or path in %v%v", ref.Name, fileSuffix))
+			}
+			if len(ref.Accepts) > 0 {
+				details = append(details,
+					fmt.Sprintf("accepts: %v", strings.Join(ref.Accepts, ", ")))
+			}
+			if len(ref.Aliases) > 0 {
+				details = append(details,
+					fmt.Sprintf("deprecated: %v", strings.Join(ref.Aliases, ", ")))
+			}
+			details = append(details, ref.Constraints...)
+
+			fmt.Fprintf(&b, "# (%v)\n", strings.Join(details, "; "))
+
+			if ref.Required {
+				fmt.Fprintf(&b, "%v=\n", ref.Name)
+			} else {
+				fmt.Fprintf(&b, "# %v=%v\n", ref.Name, ref.Default)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
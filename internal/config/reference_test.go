@@ -0,0 +1,108 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type referenceDummy struct {
+	Host    string        `name:"REF_HOST" required:"yes"`
+	Mode    string        `name:"REF_MODE" accepts:"b,a" default:"a"`
+	Timeout time.Duration `name:"REF_TIMEOUT" aliases:"REF_TIMEOUTS" min:"1s"`
+}
+
+func referenceDummyDoc(owner reflect.Type, fieldName string) string {
+	if owner == reflect.TypeOf(referenceDummy{}) && fieldName == "Host" {
+		return "Server host"
+	}
+
+	return ""
+}
+
+func TestReference(t *testing.T) {
+	refs, err := Reference(&referenceDummy{}, referenceDummyDoc)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if len(refs) != 3 {
+		t.Errorf("Expecting 3 variables, got %v", len(refs))
+		return
+	}
+
+	host, mode, timeout := refs[0], refs[1], refs[2]
+
+	if host.Name != "REF_HOST" || !host.Required || host.Doc != "Server host" {
+		t.Errorf("Unexpected host reference: %+v", host)
+	}
+
+	if mode.Type != "string" || !mode.HasDefault || mode.Default != "a" ||
+		!reflect.DeepEqual(mode.Accepts, []string{"a", "b"}) {
+		t.Errorf("Unexpected mode reference: %+v", mode)
+	}
+
+	if timeout.Type != "time.Duration" ||
+		!reflect.DeepEqual(timeout.Aliases, []string{"REF_TIMEOUTS"}) ||
+		!reflect.DeepEqual(timeout.Constraints, []string{"min=1s"}) {
+		t.Errorf("Unexpected timeout reference: %+v", timeout)
+	}
+}
+
+func TestInvalidReference(t *testing.T) {
+	if _, err := Reference(referenceDummy{}, nil); err != InvalidPointerError {
+		t.Errorf("Expecting error InvalidPointerError, got %v", err)
+	}
+}
+
+func TestWriteReference(t *testing.T) {
+	section, err := NewReferenceSection("Dummy", &referenceDummy{}, referenceDummyDoc)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	var md strings.Builder
+	if err := WriteMarkdownReference(&md, section); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedRow := "| `REF_HOST` | `string` | yes | no |  |  |  | Server host |"
+	if !strings.Contains(md.String(), expectedRow) {
+		t.Errorf("Expecting row %v in:\n%v", expectedRow, md.String())
+	}
+
+	var env strings.Builder
+	if err := WriteEnvExample(&env, section); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expectedLines := []string{
+		"# Server host", "REF_HOST=", "# REF_MODE=a",
+		"# (time.Duration; deprecated: REF_TIMEOUTS; min=1s)", "# REF_TIMEOUT=",
+	}
+
+	for _, line := range expectedLines {
+		if !strings.Contains(env.String(), line+"\n") {
+			t.Errorf("Expecting line %v in:\n%v", line, env.String())
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Unmarshaler is implemented by types that
+// know how to parse a raw variable value
+type Unmarshaler interface {
+	UnmarshalConfig(rawVal string) error
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// converters contains a converter per supported type
+var converters = struct {
+	sync.RWMutex
+	m map[reflect.Type]typeConverter
+}{
+	m: map[reflect.Type]typeConverter{
+		reflect.TypeOf(""):                    parseString,
+		reflect.TypeOf(0):                     parseInt,
+		reflect.TypeOf(int8(0)):               parseInt8,
+		reflect.TypeOf(int16(0)):              parseInt16,
+		reflect.TypeOf(int32(0)):              parseInt32,
+		reflect.TypeOf(int64(0)):              parseInt64,
+		reflect.TypeOf(uint(0)):               parseUnsignedInt,
+		reflect.TypeOf(uint8(0)):              parseUnsignedInt8,
+		reflect.TypeOf(uint16(0)):             parseUnsignedInt16,
+		reflect.TypeOf(uint32(0)):             parseUnsignedInt32,
+		reflect.TypeOf(uint64(0)):             parseUnsignedInt64,
+		reflect.TypeOf(float32(0)):            parseFloat32,
+		reflect.TypeOf(float64(0)):            parseFloat64,
+		reflect.TypeOf(time.Duration(0)):      parseDuration,
+		reflect.TypeOf(false):                 parseBool,
+		reflect.TypeOf((*utils.Addrs)(nil)):   parseAddrsRef,
+		reflect.TypeOf(utils.ByteSize(0)):     parseByteSize,
+		reflect.TypeOf(url.URL{}):             parseURL,
+		reflect.TypeOf(netip.Addr{}):          parseAddr,
+		reflect.TypeOf(netip.Prefix{}):        parsePrefix,
+		reflect.TypeOf(slog.Level(0)):         parseLogLevel,
+		reflect.TypeOf((*time.Location)(nil)): parseLocationRef,
+		reflect.TypeOf(time.Time{}):           parseTime,
+	},
+}
+
+// lookupConverter returns the converter
+// registered for t, or nil if there isn't one
+func lookupConverter(t reflect.Type) typeConverter {
+	converters.RLock()
+	defer converters.RUnlock()
+
+	return converters.m[t]
+}
+
+// RegisterConverter makes the parser support fields of type T, where parse
+// converts the raw value of a variable. It's safe to call it concurrently,
+// but it's meant to be called on package initialization, since structs
+// already parsed keep their converters. Returns RepeatedConverterError
+// if T already has a converter
+func RegisterConverter[T any](parse func(rawVal string) (T, error)) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	converters.Lock()
+	defer converters.Unlock()
+
+	if _, ok := converters.m[t]; ok {
+		return &RepeatedConverterError{typeName: t.String()}
+	}
+
+	converters.m[t] = newConverter(parse)
+
+	return nil
+}
+
+// implementsUnmarshaler tells if t implements
+// Unmarshaler or encoding.TextUnmarshaler
+func implementsUnmarshaler(t reflect.Type) bool {
+	return t.Implements(unmarshalerType) || t.Implements(textUnmarshalerType)
+}
+
+// unmarshal parses the raw value with the method implemented by
+// ptr, where Unmarshaler has precedence over encoding.TextUnmarshaler
+func unmarshal(ptr reflect.Value, rawVal string) error {
+	switch u := ptr.Interface().(type) {
+	case Unmarshaler:
+		return u.UnmarshalConfig(rawVal)
+	case encoding.TextUnmarshaler:
+		return u.UnmarshalText([]byte(rawVal))
+	default:
+		return nil // Never happens, see selectUnmarshalerConverter
+	}
+}
+
+// selectUnmarshalerConverter returns a converter if t (or a pointer to
+// t) implements Unmarshaler or encoding.TextUnmarshaler. If t is a pointer,
+// the converter allocates a new value before parsing the raw value
+func selectUnmarshalerConverter(t reflect.Type) typeConverter {
+	switch {
+	case implementsUnmarshaler(reflect.PointerTo(t)):
+		return func(vRep *reflect.Value, rawVal string) error {
+			ptr := reflect.New(t)
+			if err := unmarshal(ptr, rawVal); err != nil {
+				return err
+			}
+
+			vRep.Set(ptr.Elem())
+
+			return nil
+		}
+	case t.Kind() == reflect.Pointer && implementsUnmarshaler(t):
+		return func(vRep *reflect.Value, rawVal string) error {
+			ptr := reflect.New(t.Elem())
+			if err := unmarshal(ptr, rawVal); err != nil {
+				return err
+			}
+
+			vRep.Set(ptr)
+
+			return nil
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars/providers"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type dummyLevel int
+
+type dummyColor string
+
+func (c *dummyColor) UnmarshalConfig(rawVal string) error {
+	if rawVal != "red" && rawVal != "blue" {
+		return fmt.Errorf("unknown color %v", rawVal)
+	}
+
+	*c = dummyColor(strings.ToUpper(rawVal))
+
+	return nil
+}
+
+type dummyName struct {
+	first, last string
+}
+
+func (n *dummyName) UnmarshalText(text []byte) error {
+	first, last, ok := strings.Cut(string(text), " ")
+	if !ok {
+		return fmt.Errorf("expecting first and last name")
+	}
+
+	n.first, n.last = first, last
+
+	return nil
+}
+
+func (n *dummyName) UnmarshalConfig(rawVal string) error {
+	n.first, n.last = rawVal, rawVal
+
+	return nil
+}
+
+func TestRegisterConverter(t *testing.T) {
+	levels := map[string]dummyLevel{"low": 1, "high": 2}
+
+	err := RegisterConverter(func(rawVal string) (dummyLevel, error) {
+		if l, ok := levels[rawVal]; ok {
+			return l, nil
+		}
+
+		return 0, fmt.Errorf("unknown level %v", rawVal)
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	var l dummyLevel
+
+	v := reflect.ValueOf(&l).Elem()
+	converter := selectConverter(v.Type(), defaultSeparator)
+	if converter == nil {
+		t.Error("Expecting converter of registered type")
+		return
+	}
+
+	if err := converter(&v, "high"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if l != 2 {
+		t.Errorf("Expecting assign value 2, got %v", l)
+	}
+
+	if err := converter(&v, "medium"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestRepeatedConverter(t *testing.T) {
+	err := RegisterConverter(func(rawVal string) (string, error) {
+		return rawVal, nil
+	})
+	if _, ok := err.(*RepeatedConverterError); !ok {
+		t.Errorf("Expecting error RepeatedConverterError, got %v", err)
+	}
+}
+
+func TestUnmarshalerConverter(t *testing.T) {
+	var c dummyColor
+
+	v := reflect.ValueOf(&c).Elem()
+	converter := selectConverter(v.Type(), defaultSeparator)
+	if converter == nil {
+		t.Error("Expecting converter of Unmarshaler type")
+		return
+	}
+
+	if err := converter(&v, "red"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if c != "RED" {
+		t.Errorf("Expecting assign value RED, got %v", c)
+	}
+
+	if err := converter(&v, "pink"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestTextUnmarshalerConverter(t *testing.T) {
+	var a netip.Addr
+
+	v := reflect.ValueOf(&a).Elem()
+	converter := selectConverter(v.Type(), defaultSeparator)
+	if converter == nil {
+		t.Error("Expecting converter of encoding.TextUnmarshaler type")
+		return
+	}
+
+	if err := converter(&v, "127.0.0.1"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if a.String() != "127.0.0.1" {
+		t.Errorf("Expecting assign value 127.0.0.1, got %v", a)
+	}
+
+	if err := converter(&v, "lol"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestUnmarshalerPrecedence(t *testing.T) {
+	var n *dummyName
+
+	v := reflect.ValueOf(&n).Elem()
+	converter := selectConverter(v.Type(), defaultSeparator)
+	if converter == nil {
+		t.Error("Expecting converter of pointer to Unmarshaler type")
+		return
+	}
+
+	if err := converter(&v, "joe"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if n == nil || n.first != "joe" || n.last != "joe" {
+		t.Errorf("Expecting value parsed by UnmarshalConfig, got %v", n)
+	}
+}
+
+func TestUnmarshalerParseConf(t *testing.T) {
+	type Dummy struct {
+		C  dummyColor   `name:"UNMARSHALER_1" accepts:"red"`
+		Cs []dummyColor `name:"UNMARSHALER_2" default:"red,blue"`
+	}
+
+	setVars(map[string]string{
+		"UNMARSHALER_1": "red",
+	})
+	defer unsetVars("UNMARSHALER_1")
+
+	cp, _ := New(envvars.New(providers.NewEnvVariables()))
+
+	d := &Dummy{}
+	if err := cp.ParseConf(d); err != nil {
+		t.Errorf("Unexpected error from config parser: %v", err)
+		return
+	}
+
+	if d.C != "RED" {
+		t.Errorf("Expecting C field with RED, got %v", d.C)
+	}
+
+	if len(d.Cs) != 2 || d.Cs[0] != "RED" || d.Cs[1] != "BLUE" {
+		t.Errorf("Expecting Cs field with [RED BLUE], got %v", d.Cs)
+	}
+}
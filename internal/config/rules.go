@@ -0,0 +1,291 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"reflect"
+	"strings"
+)
+
+// varCondition represents a condition NAME=value of required_if. The
+// value is converted to the type of the target variable on parsing
+type varCondition struct {
+	name     string
+	rawValue string
+	target   *variableInfo
+	value    reflect.Value
+}
+
+// holds tells if the target field of srtVal has the value of the condition
+func (c *varCondition) holds(srtVal *reflect.Value) bool {
+	return reflect.DeepEqual(c.target.fieldOf(srtVal).Interface(), c.value.Interface())
+}
+
+// varRules contains the requirements of a variable
+// that depend on other variables. Names are resolved
+// against the prefix of the struct that owns the field
+type varRules struct {
+	requiredIf []*varCondition
+	excludes   []string
+	excluded   []*variableInfo
+	group      string
+}
+
+// parseFieldRules parses required_if, excludes and one_of_group, prepending
+// prefix to each referenced name. Returns ConflictingTagKeysError if
+// they're used along with required, or required_if along with default
+func parseFieldRules(v *variableInfo, field *reflect.StructField, prefix string) error {
+	requiredIf, err := parseTagKeyRequiredIf(field)
+	if err != nil {
+		return err
+	}
+	for _, condition := range requiredIf {
+		condition.name = prefix + condition.name
+	}
+
+	excludes, err := parseTagKeyVarNames(field, excludesTagKey)
+	if err != nil {
+		return err
+	}
+	for i := range excludes {
+		excludes[i] = prefix + excludes[i]
+	}
+
+	group, err := parseTagKeyOneOfGroup(field)
+	if err != nil {
+		return err
+	}
+	if group != "" {
+		group = prefix + group
+	}
+
+	conflicting := func(keyNames ...string) error {
+		return &ConflictingTagKeysError{
+			fieldName: v.fieldPath,
+			keyNames:  keyNames,
+		}
+	}
+
+	switch {
+	case v.required && requiredIf != nil:
+		return conflicting(requiredTagKey, requiredIfTagKey)
+	case v.required && group != "":
+		return conflicting(requiredTagKey, oneOfGroupTagKey)
+	case v.hasDefault && requiredIf != nil:
+		return conflicting(defaultTagKey, requiredIfTagKey)
+	}
+
+	if requiredIf != nil || excludes != nil || group != "" {
+		v.rules = &varRules{
+			requiredIf: requiredIf,
+			excludes:   excludes,
+			group:      group,
+		}
+	}
+
+	return nil
+}
+
+// resolveReference returns the variable named name. Returns
+// UnknownVarReferenceError if there isn't such variable or
+// InvalidTagKeyValueFmtError if v references itself
+func resolveReference(
+	v *variableInfo,
+	key, name string,
+	byName map[string]*variableInfo,
+) (*variableInfo, error) {
+	target, ok := byName[name]
+	if !ok {
+		return nil, &UnknownVarReferenceError{
+			fieldName: v.fieldPath,
+			keyName:   key,
+			varName:   name,
+		}
+	}
+
+	if target == v {
+		return nil, &InvalidTagKeyValueFmtError{
+			fieldName: v.fieldPath,
+			keyName:   key,
+			rawValue:  name,
+			reason:    "variable can't reference itself",
+		}
+	}
+
+	return target, nil
+}
+
+// resolveRules links the variables referenced in the rules of vars,
+// converting the value of each condition to the type of its target.
+// Returns UnknownVarReferenceError if some variable doesn't exist or
+// InvalidTagKeyValueFmtError if a condition value doesn't match the
+// target type or a group has less than two variables
+func resolveRules(vars []*variableInfo) error {
+	byName := make(map[string]*variableInfo, len(vars))
+	for _, v := range vars {
+		byName[v.name] = v
+	}
+
+	var groups []string
+	members := make(map[string][]*variableInfo)
+
+	for _, v := range vars {
+		r := v.rules
+		if r == nil {
+			continue
+		}
+
+		for _, condition := range r.requiredIf {
+			target, err := resolveReference(v, requiredIfTagKey, condition.name, byName)
+			if err != nil {
+				return err
+			}
+
+			value := reflect.New(target.fieldType).Elem()
+			if err := target.setValue(&value, condition.rawValue); err != nil {
+				return &InvalidTagKeyValueFmtError{
+					fieldName: v.fieldPath,
+					keyName:   requiredIfTagKey,
+					rawValue:  condition.rawValue,
+					reason:    fmt.Sprintf("doesn't match type of variable %v", target.name),
+				}
+			}
+
+			condition.target, condition.value = target, value
+		}
+
+		r.excluded = nil
+		for _, name := range r.excludes {
+			target, err := resolveReference(v, excludesTagKey, name, byName)
+			if err != nil {
+				return err
+			}
+
+			r.excluded = append(r.excluded, target)
+		}
+
+		if r.group == "" {
+			continue
+		}
+
+		if _, ok := members[r.group]; !ok {
+			groups = append(groups, r.group)
+		}
+		members[r.group] = append(members[r.group], v)
+	}
+
+	for _, group := range groups {
+		if first := members[group][0]; len(members[group]) < 2 {
+			return &InvalidTagKeyValueFmtError{
+				fieldName: first.fieldPath,
+				keyName:   oneOfGroupTagKey,
+				rawValue:  group,
+				reason:    "group must have at least two variables",
+			}
+		}
+	}
+
+	return nil
+}
+
+// varNames returns the names of vars separated by a comma
+func (cp *ConfParser) varNames(vars []*variableInfo) string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = cp.varName(v.name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// checkRules verifies the rules of each variable, where holds tells if a
+// condition holds given the filled fields, as well as the variables
+// defined in the config reader and the ones that couldn't be filled.
+// Conditions that depend on the latter are ignored. Errors are wrapped
+// by errorw.Wrapper, with ErrorCodeMissingVar or ErrorCodeConflictingVars
+func (cp *ConfParser) checkRules(
+	vars []*variableInfo,
+	holds func(c *varCondition) bool,
+	defined, failed map[*variableInfo]bool,
+) []error {
+	var errs []error
+
+	var groups []string
+	members := make(map[string][]*variableInfo)
+
+	for _, v := range vars {
+		r := v.rules
+		if r == nil {
+			continue
+		}
+
+		for _, condition := range r.requiredIf {
+			if defined[v] || failed[condition.target] || !holds(condition) {
+				continue
+			}
+
+			errs = append(errs, errorw.WrapErrorf(
+				ErrorCodeMissingVar, nil, "Missing variable %v, required when %v=%v",
+				cp.varName(v.name), cp.varName(condition.target.name), condition.rawValue))
+
+			break
+		}
+
+		for _, excluded := range r.excluded {
+			if defined[v] && defined[excluded] {
+				errs = append(errs, errorw.WrapErrorf(
+					ErrorCodeConflictingVars, nil,
+					"Variable %v can't be defined along with %v",
+					cp.varName(v.name), cp.varName(excluded.name)))
+			}
+		}
+
+		if r.group == "" {
+			continue
+		}
+
+		if _, ok := members[r.group]; !ok {
+			groups = append(groups, r.group)
+		}
+		members[r.group] = append(members[r.group], v)
+	}
+
+	for _, group := range groups {
+		var definedMembers []*variableInfo
+		for _, v := range members[group] {
+			if defined[v] {
+				definedMembers = append(definedMembers, v)
+			}
+		}
+
+		switch len(definedMembers) {
+		case 1:
+		case 0:
+			errs = append(errs, errorw.WrapErrorf(
+				ErrorCodeMissingVar, nil, "One of variables %v must be defined",
+				cp.varNames(members[group])))
+		default:
+			errs = append(errs, errorw.WrapErrorf(
+				ErrorCodeConflictingVars, nil, "Only one of variables %v can be defined",
+				cp.varNames(definedMembers)))
+		}
+	}
+
+	return errs
+}
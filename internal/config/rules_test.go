@@ -0,0 +1,249 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"reflect"
+	"testing"
+)
+
+type rulesTls struct {
+	UseTls bool   `name:"TLS"`
+	Cert   string `name:"TLS_CERT" required_if:"TLS=true"`
+}
+
+type rulesDummy struct {
+	Tls      rulesTls `prefix:"RULES_"`
+	Password string   `name:"RULES_PASSWORD" one_of_group:"AUTH" excludes:"RULES_TOKEN"`
+	Token    string   `name:"RULES_TOKEN" one_of_group:"AUTH"`
+}
+
+var rulesVars = []string{"RULES_TLS", "RULES_TLS_CERT", "RULES_PASSWORD", "RULES_TOKEN"}
+
+func TestValidRules(t *testing.T) {
+	st := reflect.ValueOf(&rulesDummy{}).Elem()
+
+	vs, err := parseFields(st.Type())
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	condition := vs[1].rules.requiredIf[0]
+	if condition.target != vs[0] || !reflect.DeepEqual(condition.value.Interface(), true) {
+		t.Errorf("Expecting condition to reference RULES_TLS=true, got %+v", condition)
+	}
+
+	if excluded := vs[2].rules.excluded; len(excluded) != 1 || excluded[0] != vs[3] {
+		t.Errorf("Expecting RULES_PASSWORD to exclude RULES_TOKEN, got %v", excluded)
+	}
+
+	if vs[2].rules.group != "AUTH" || vs[3].rules.group != "AUTH" {
+		t.Error("Expecting variables in group AUTH")
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	testBattery := []struct {
+		name   string
+		srtPtr any
+		check  func(err error) bool
+	}{
+		{
+			name: "TestUnknownRequiredIfVar",
+			srtPtr: &struct {
+				A string `name:"A" required_if:"B=1"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*UnknownVarReferenceError)
+				return ok
+			},
+		},
+		{
+			name: "TestUnknownExcludedVar",
+			srtPtr: &struct {
+				A string `name:"A" excludes:"B"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*UnknownVarReferenceError)
+				return ok
+			},
+		},
+		{
+			name: "TestNameWithoutPrefix",
+			srtPtr: &struct {
+				T rulesTls `prefix:"P_"`
+				A string   `name:"A" required_if:"TLS=true"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*UnknownVarReferenceError)
+				return ok
+			},
+		},
+		{
+			name: "TestSelfReference",
+			srtPtr: &struct {
+				A string `name:"A" excludes:"A"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*InvalidTagKeyValueFmtError)
+				return ok
+			},
+		},
+		{
+			name: "TestConditionTypeMismatch",
+			srtPtr: &struct {
+				A string `name:"A" required_if:"B=bark"`
+				B int    `name:"B"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*InvalidTagKeyValueFmtError)
+				return ok
+			},
+		},
+		{
+			name: "TestSingleVarGroup",
+			srtPtr: &struct {
+				A string `name:"A" one_of_group:"G"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*InvalidTagKeyValueFmtError)
+				return ok
+			},
+		},
+		{
+			name: "TestRequiredWithRequiredIf",
+			srtPtr: &struct {
+				A string `name:"A" required:"yes" required_if:"B=1"`
+				B int    `name:"B"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*ConflictingTagKeysError)
+				return ok
+			},
+		},
+		{
+			name: "TestDefaultWithRequiredIf",
+			srtPtr: &struct {
+				A string `name:"A" default:"a" required_if:"B=1"`
+				B int    `name:"B"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*ConflictingTagKeysError)
+				return ok
+			},
+		},
+		{
+			name: "TestRequiredWithGroup",
+			srtPtr: &struct {
+				A string `name:"A" required:"yes" one_of_group:"G"`
+				B string `name:"B" one_of_group:"G"`
+			}{},
+			check: func(err error) bool {
+				_, ok := err.(*ConflictingTagKeysError)
+				return ok
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			st := reflect.ValueOf(pair.srtPtr).Elem()
+
+			if _, err := parseFields(st.Type()); !pair.check(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRulesParseConf(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		vars     map[string]string
+		expected []errorw.ErrorCode
+	}{
+		{
+			name:     "TestValid",
+			vars:     map[string]string{"RULES_TLS": "true", "RULES_TLS_CERT": "c", "RULES_TOKEN": "t"},
+			expected: nil,
+		},
+		{
+			name:     "TestConditionNotMet",
+			vars:     map[string]string{"RULES_TLS": "false", "RULES_PASSWORD": "p"},
+			expected: nil,
+		},
+		{
+			name:     "TestMissingRequiredIf",
+			vars:     map[string]string{"RULES_TLS": "1", "RULES_TOKEN": "t"},
+			expected: []errorw.ErrorCode{ErrorCodeMissingVar},
+		},
+		{
+			name:     "TestInvalidConditionTarget",
+			vars:     map[string]string{"RULES_TLS": "bark", "RULES_TOKEN": "t"},
+			expected: []errorw.ErrorCode{ErrorCodeInvalidVarType},
+		},
+		{
+			name:     "TestMissingGroup",
+			vars:     map[string]string{},
+			expected: []errorw.ErrorCode{ErrorCodeMissingVar},
+		},
+		{
+			name: "TestExcludedAndGroup",
+			vars: map[string]string{"RULES_PASSWORD": "p", "RULES_TOKEN": "t"},
+			expected: []errorw.ErrorCode{
+				ErrorCodeConflictingVars, ErrorCodeConflictingVars},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			setVars(pair.vars)
+			defer unsetVars(rulesVars...)
+
+			cp, _ := New(envvars.New(&FakeProvider{}))
+
+			pErr := cp.ParseConf(&rulesDummy{})
+			if pair.expected == nil {
+				if pErr != nil {
+					t.Errorf("Unexpected error: %v", pErr)
+				}
+				return
+			}
+
+			vErr, ok := pErr.(*VariablesError)
+			if !ok {
+				t.Errorf("Expecting error of type VariablesError, got %v", pErr)
+				return
+			}
+
+			errs := vErr.Errors()
+			if len(errs) != len(pair.expected) {
+				t.Errorf("Expecting %v aggregated errors, got %v", len(pair.expected), vErr)
+				return
+			}
+
+			for i, code := range pair.expected {
+				if err, ok := errs[i].(*errorw.Wrapper); !ok || err.Code() != code {
+					t.Errorf("Expecting error with code %v at position %v, got %v", code, i, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"sort"
+	"strings"
+)
+
+// strictPrefixSeparator ends the prefix of a variable in strict mode
+const strictPrefixSeparator = "_"
+
+// strictPrefixes returns the sorted prefixes where unknown variables are
+// searched, i.e. the name of each variable up to the first underscore
+// (inclusive), after the namespace. Variables without it are ignored
+func (cp *ConfParser) strictPrefixes(vars []*variableInfo) []string {
+	unique := make(map[string]bool)
+
+	for _, v := range vars {
+		for _, name := range v.names() {
+			segment, _, found := strings.Cut(name, strictPrefixSeparator)
+			if found {
+				unique[cp.varName(segment+strictPrefixSeparator)] = true
+			}
+		}
+	}
+
+	prefixes := make([]string, 0, len(unique))
+	for prefix := range unique {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	return prefixes
+}
+
+// claimedNames returns the names of the config reader
+// that are read by vars, including aliases and files
+func (cp *ConfParser) claimedNames(vars []*variableInfo) map[string]bool {
+	claimed := make(map[string]bool)
+
+	for _, v := range vars {
+		for _, name := range v.names() {
+			claimed[cp.varName(name)] = true
+
+			if v.secret {
+				claimed[cp.varName(name+fileSuffix)] = true
+			}
+		}
+	}
+
+	return claimed
+}
+
+// suggestName returns the name of the variable closest to unknown,
+// by edit distance, if it's close enough to be considered a typo
+func (cp *ConfParser) suggestName(unknown string, vars []*variableInfo) (string, bool) {
+	// Tolerates roughly one typo per four characters
+	maxDistance := len(unknown)/4 + 1
+
+	suggestion, bestDistance := "", maxDistance+1
+
+	for _, v := range vars {
+		name := cp.varName(v.name)
+
+		if distance := utils.EditDistance(unknown, name); distance < bestDistance {
+			suggestion, bestDistance = name, distance
+		}
+	}
+
+	return suggestion, suggestion != ""
+}
+
+// checkUnknown lists the variables of the config reader under the prefixes
+// of vars that aren't read by any of them, if the parser is strict. Errors
+// are wrapped by errorw.Wrapper, with ErrorCodeUnknownVar or with
+// ErrorCodeInvalidGetVar if the variables can't be listed
+func (cp *ConfParser) checkUnknown(vars []*variableInfo) []error {
+	if !cp.strict {
+		return nil
+	}
+
+	var errs []error
+
+	claimed := cp.claimedNames(vars)
+
+	for _, prefix := range cp.strictPrefixes(vars) {
+		keys, err := cp.reader.Keys(prefix)
+		if err != nil {
+			errs = append(errs, errorw.WrapErrorf(
+				ErrorCodeInvalidGetVar, err,
+				"Error while trying to list variables with prefix %v", prefix))
+
+			continue
+		}
+
+		for _, key := range keys {
+			if claimed[key] {
+				continue
+			}
+
+			if suggestion, ok := cp.suggestName(key, vars); ok {
+				errs = append(errs, errorw.WrapErrorf(
+					ErrorCodeUnknownVar, nil,
+					"Unknown variable %v, did you mean %v?", key, suggestion))
+			} else {
+				errs = append(errs, errorw.WrapErrorf(
+					ErrorCodeUnknownVar, nil, "Unknown variable %v", key))
+			}
+		}
+	}
+
+	return errs
+}
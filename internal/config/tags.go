@@ -21,14 +21,28 @@ import (
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // Available tags
 const (
-	nameTagKey     = "name"
-	requiredTagKey = "required"
-	acceptsTagKey  = "accepts"
+	nameTagKey       = "name"
+	requiredTagKey   = "required"
+	acceptsTagKey    = "accepts"
+	prefixTagKey     = "prefix"
+	defaultTagKey    = "default"
+	sepTagKey        = "sep"
+	minTagKey        = "min"
+	maxTagKey        = "max"
+	patternTagKey    = "pattern"
+	lenTagKey        = "len"
+	secretTagKey     = "secret"
+	reloadTagKey     = "reload"
+	requiredIfTagKey = "required_if"
+	excludesTagKey   = "excludes"
+	oneOfGroupTagKey = "one_of_group"
+	aliasesTagKey    = "aliases"
 )
 
 // lookupKey searches for the key in the tag of a given field
@@ -68,24 +82,84 @@ func parseTagKeyName(field *reflect.StructField) (string, error) {
 	return name, nil
 }
 
+// parseTagKeyPrefix fetches the prefix of a nested struct. If it isn't
+// present returns an empty string. Returns InvalidTagKeyValueFmtError
+// if it's empty or doesn't match the variable's name format
+func parseTagKeyPrefix(field *reflect.StructField) (string, error) {
+	prefix, ok := lookupKey(field, prefixTagKey)
+	if !ok {
+		return "", nil
+	}
+
+	if !varNameRegex.MatchString(prefix) {
+		return "", &InvalidTagKeyValueFmtError{
+			fieldName: field.Name,
+			keyName:   prefixTagKey,
+			rawValue:  prefix,
+			reason: fmt.Sprintf(
+				"invalid prefix; accepted pattern: %v",
+				varNameRegex.String()),
+		}
+	}
+
+	return prefix, nil
+}
+
+// parseBoolTagKey returns the value of a boolean tag key and tells if it is
+// present. If the value wasn't recognized returns InvalidTagKeyValueError
+func parseBoolTagKey(field *reflect.StructField, key string) (bool, bool, error) {
+	value, ok := lookupKey(field, key)
+	if !ok {
+		return false, false, nil
+	}
+
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		return true, true, nil
+	case "no", "false":
+		return false, true, nil
+	default:
+		return false, false, &InvalidTagKeyValueError{
+			fieldName:      field.Name,
+			keyName:        key,
+			acceptedValues: []string{"yes", "no", "true", "false"},
+		}
+	}
+}
+
 // parseTagKeyRequired returns true if set, otherwise false. If field is invalid
 // or the value wasn't recognized returns false and InvalidTagKeyValueError
 func parseTagKeyRequired(field *reflect.StructField) (bool, error) {
-	if required, ok := lookupKey(field, requiredTagKey); ok {
-		switch strings.ToLower(required) {
-		case "yes", "true":
-			return true, nil
-		case "no", "false": // false by default
-		default:
-			return false, &InvalidTagKeyValueError{
-				fieldName:      field.Name,
-				keyName:        requiredTagKey,
-				acceptedValues: []string{"yes", "no", "true", "false"},
-			}
-		}
+	required, _, err := parseBoolTagKey(field, requiredTagKey)
+
+	return required, err // false by default
+}
+
+// secretSuffix identifies secret variables by convention
+const secretSuffix = "_SECRET"
+
+// parseTagKeySecret tells if the variable holds a secret. If the tag key
+// is missing, variables whose name ends with secretSuffix are secrets.
+// If the value wasn't recognized returns false and InvalidTagKeyValueError
+func parseTagKeySecret(field *reflect.StructField, varName string) (bool, error) {
+	secret, ok, err := parseBoolTagKey(field, secretTagKey)
+	if err != nil || ok {
+		return secret, err
+	}
+
+	return strings.HasSuffix(varName, secretSuffix), nil
+}
+
+// parseTagKeyReload tells if the variable can be changed by a Watcher reload.
+// Returns true if the tag key is missing. If the value wasn't recognized
+// returns false and InvalidTagKeyValueError
+func parseTagKeyReload(field *reflect.StructField) (bool, error) {
+	reload, ok, err := parseBoolTagKey(field, reloadTagKey)
+	if err != nil || ok {
+		return reload, err
 	}
 
-	return false, nil
+	return true, nil
 }
 
 // parseTagKeyAccepts parses each accepted value and returns a set with them.
@@ -147,3 +221,249 @@ func parseTagKeyAccepts(field *reflect.StructField) (*utils.Set[string], error)
 
 	return validTokens, nil
 }
+
+// parseTagKeyDefault fetches the default value and tells if it is present.
+// If the value is empty returns InvalidTagKeyValueError. Keep in mind that
+// the value isn't checked against the field type
+func parseTagKeyDefault(field *reflect.StructField) (string, bool, error) {
+	defaultVal, ok := lookupKey(field, defaultTagKey)
+	if !ok {
+		return "", false, nil
+	}
+
+	if defaultVal == "" {
+		return "", false, &InvalidTagKeyValueError{
+			fieldName:      field.Name,
+			keyName:        defaultTagKey,
+			acceptedValues: []string{"any non-empty value of the field type"},
+		}
+	}
+
+	return defaultVal, true, nil
+}
+
+// parseTagKeySep fetches the separator of collection elements. If it
+// isn't present returns an empty string. Returns InvalidTagKeyValueError
+// if the value is empty
+func parseTagKeySep(field *reflect.StructField) (string, error) {
+	sep, ok := lookupKey(field, sepTagKey)
+	if !ok {
+		return "", nil
+	}
+
+	if sep == "" {
+		return "", &InvalidTagKeyValueError{
+			fieldName:      field.Name,
+			keyName:        sepTagKey,
+			acceptedValues: []string{"any non-blank separator, e.g. ;"},
+		}
+	}
+
+	return sep, nil
+}
+
+// parseTagKeyBound fetches the raw value of a min or max bound and tells if it
+// is present. If the value is empty returns InvalidTagKeyValueError. Keep in
+// mind that the value isn't checked against the field type
+func parseTagKeyBound(field *reflect.StructField, key string) (string, bool, error) {
+	bound, ok := lookupKey(field, key)
+	if !ok {
+		return "", false, nil
+	}
+
+	if bound == "" {
+		return "", false, &InvalidTagKeyValueError{
+			fieldName:      field.Name,
+			keyName:        key,
+			acceptedValues: []string{"any non-empty value of the field type"},
+		}
+	}
+
+	return bound, true, nil
+}
+
+// parseTagKeyPattern compiles the regular expression that values must match.
+// If it isn't present returns nil. Returns InvalidTagKeyValueError if the
+// value is empty or InvalidTagKeyValueFmtError if it doesn't compile
+func parseTagKeyPattern(field *reflect.StructField) (*regexp.Regexp, error) {
+	pattern, ok := lookupKey(field, patternTagKey)
+	if !ok {
+		return nil, nil
+	}
+
+	if pattern == "" {
+		return nil, &InvalidTagKeyValueError{
+			fieldName:      field.Name,
+			keyName:        patternTagKey,
+			acceptedValues: []string{"any regular expression, e.g. ^[a-z]+$"},
+		}
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &InvalidTagKeyValueFmtError{
+			fieldName: field.Name,
+			keyName:   patternTagKey,
+			rawValue:  pattern,
+			reason:    err.Error(),
+		}
+	}
+
+	return compiled, nil
+}
+
+// lenRangeSeparator splits the lower and upper bounds of len
+const lenRangeSeparator = ".."
+
+// parseTagKeyLen fetches the length bounds in the format N (exact length)
+// or N..M, where one of the bounds can be omitted. An unbounded maximum is
+// returned as -1. Tells if it is present. Returns InvalidTagKeyValueFmtError
+// if the format is invalid or the minimum is greater than the maximum
+func parseTagKeyLen(field *reflect.StructField) (int, int, bool, error) {
+	rawLen, ok := lookupKey(field, lenTagKey)
+	if !ok {
+		return 0, -1, false, nil
+	}
+
+	invalidFmt := func(reason string) (int, int, bool, error) {
+		return 0, -1, false, &InvalidTagKeyValueFmtError{
+			fieldName: field.Name,
+			keyName:   lenTagKey,
+			rawValue:  rawLen,
+			reason:    reason,
+		}
+	}
+
+	parseBound := func(raw string, unbounded int) (int, bool) {
+		if raw = utils.PolishString(raw); raw == "" {
+			return unbounded, true
+		}
+
+		bound, err := strconv.ParseUint(raw, 10, 31)
+
+		return int(bound), err == nil
+	}
+
+	rawMin, rawMax, isRange := strings.Cut(rawLen, lenRangeSeparator)
+	if !isRange {
+		rawMax = rawMin
+	}
+
+	if utils.PolishString(rawMin) == "" && utils.PolishString(rawMax) == "" {
+		return invalidFmt("expecting N, N..M, N.. or ..M")
+	}
+
+	minLen, okMin := parseBound(rawMin, 0)
+	maxLen, okMax := parseBound(rawMax, -1)
+	if !okMin || !okMax {
+		return invalidFmt("bounds must be non-negative integers")
+	}
+
+	if maxLen != -1 && minLen > maxLen {
+		return invalidFmt("minimum length is greater than the maximum")
+	}
+
+	return minLen, maxLen, true, nil
+}
+
+// conditionSeparator splits the variable's name from the value in conditions
+const conditionSeparator = "="
+
+// parseTagKeyVarNames fetches a list of variable names separated by a
+// comma. If it isn't present returns nil. Returns InvalidTagKeyValueFmtError
+// if some element is empty or doesn't match the variable's name format
+func parseTagKeyVarNames(field *reflect.StructField, key string) ([]string, error) {
+	rawNames, ok := lookupKey(field, key)
+	if !ok {
+		return nil, nil
+	}
+
+	names, ok := utils.SplitList(rawNames, ",")
+	if ok {
+		for _, name := range names {
+			ok = ok && varNameRegex.MatchString(name)
+		}
+	}
+
+	if !ok {
+		return nil, &InvalidTagKeyValueFmtError{
+			fieldName: field.Name,
+			keyName:   key,
+			rawValue:  rawNames,
+			reason: fmt.Sprintf(
+				"expecting variable names separated by a comma; accepted pattern: %v",
+				varNameRegex.String()),
+		}
+	}
+
+	return names, nil
+}
+
+// parseTagKeyRequiredIf fetches the conditions in the format NAME=value,
+// separated by a comma. If it isn't present returns nil. Returns
+// InvalidTagKeyValueFmtError if some condition is bad formatted
+func parseTagKeyRequiredIf(field *reflect.StructField) ([]*varCondition, error) {
+	rawConditions, ok := lookupKey(field, requiredIfTagKey)
+	if !ok {
+		return nil, nil
+	}
+
+	invalidFmt := &InvalidTagKeyValueFmtError{
+		fieldName: field.Name,
+		keyName:   requiredIfTagKey,
+		rawValue:  rawConditions,
+		reason: fmt.Sprintf(
+			"expecting conditions in the format NAME%vvalue separated by a comma",
+			conditionSeparator),
+	}
+
+	elems, ok := utils.SplitList(rawConditions, ",")
+	if !ok {
+		return nil, invalidFmt
+	}
+
+	conditions := make([]*varCondition, len(elems))
+
+	for i, elem := range elems {
+		name, value, found := strings.Cut(elem, conditionSeparator)
+		name, value = utils.PolishString(name), utils.PolishString(value)
+
+		if !found || !varNameRegex.MatchString(name) || value == "" {
+			return nil, invalidFmt
+		}
+
+		conditions[i] = &varCondition{name: name, rawValue: value}
+	}
+
+	return conditions, nil
+}
+
+// parseTagKeyOneOfGroup fetches the name of the group. If it isn't present
+// returns an empty string. Returns InvalidTagKeyValueFmtError if it's
+// empty or doesn't match the variable's name format
+func parseTagKeyOneOfGroup(field *reflect.StructField) (string, error) {
+	group, ok := lookupKey(field, oneOfGroupTagKey)
+	if !ok {
+		return "", nil
+	}
+
+	if !varNameRegex.MatchString(group) {
+		return "", &InvalidTagKeyValueFmtError{
+			fieldName: field.Name,
+			keyName:   oneOfGroupTagKey,
+			rawValue:  group,
+			reason: fmt.Sprintf(
+				"invalid group name; accepted pattern: %v",
+				varNameRegex.String()),
+		}
+	}
+
+	return group, nil
+}
+
+// parseTagKeyAliases fetches the deprecated names of the variable,
+// separated by a comma. If it isn't present returns nil. Returns
+// InvalidTagKeyValueFmtError if some name is bad formatted
+func parseTagKeyAliases(field *reflect.StructField) ([]string, error) {
+	return parseTagKeyVarNames(field, aliasesTagKey)
+}
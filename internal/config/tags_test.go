@@ -410,3 +410,282 @@ func TestInvalidTagKeyAcceptsParsing(t *testing.T) {
 		t.Run(pair.name, pair.test)
 	}
 }
+
+func TestValidTagKeyPrefixParsing(t *testing.T) {
+	checkReturn := func(t *testing.T, expected string, srtPtr any) {
+		sf := reflect.TypeOf(srtPtr).Elem().Field(0)
+
+		prefix, err := parseTagKeyPrefix(&sf)
+		if err != nil {
+			t.Errorf("Expecting nil err, got %v", err)
+		}
+
+		if prefix != expected {
+			t.Errorf("Expecting prefix %v, got %v", expected, prefix)
+		}
+	}
+
+	testBattery := []struct {
+		name string
+		test func(t *testing.T)
+	}{
+		{
+			name: "TestWithoutTag",
+			test: func(t *testing.T) {
+				checkReturn(t, "", &struct {
+					S struct{}
+				}{})
+			},
+		},
+		{
+			name: "TestWithPrefix",
+			test: func(t *testing.T) {
+				checkReturn(t, "POSTGRES_", &struct {
+					S struct{} `prefix:"  POSTGRES_ "`
+				}{})
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, pair.test)
+	}
+}
+
+func TestInvalidTagKeyPrefixParsing(t *testing.T) {
+	checkError := func(t *testing.T, srtPtr any) {
+		sf := reflect.TypeOf(srtPtr).Elem().Field(0)
+
+		prefix, err := parseTagKeyPrefix(&sf)
+		if _, ok := err.(*InvalidTagKeyValueFmtError); !ok {
+			t.Errorf("Expecting err InvalidTagKeyValueFmtError, got %v", err)
+		}
+
+		if prefix != "" {
+			t.Errorf("Expecting empty prefix, got %v", prefix)
+		}
+	}
+
+	testBattery := []struct {
+		name string
+		test func(t *testing.T)
+	}{
+		{
+			name: "TestWithEmptyValue",
+			test: func(t *testing.T) {
+				checkError(t, &struct {
+					S struct{} `prefix:""`
+				}{})
+			},
+		},
+		{
+			name: "TestWithInvalidValue",
+			test: func(t *testing.T) {
+				checkError(t, &struct {
+					S struct{} `prefix:"1_A"`
+				}{})
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, pair.test)
+	}
+}
+
+func TestValidTagKeyDefaultParsing(t *testing.T) {
+	checkReturn := func(t *testing.T, expected string, present bool, srtPtr any) {
+		sf := reflect.TypeOf(srtPtr).Elem().Field(0)
+
+		defaultVal, ok, err := parseTagKeyDefault(&sf)
+		if err != nil {
+			t.Errorf("Expecting nil err, got %v", err)
+		}
+
+		if ok != present {
+			t.Errorf("Expecting presence to be %v in tag %v", present, sf.Tag)
+		}
+
+		if defaultVal != expected {
+			t.Errorf("Expecting default %v, got %v", expected, defaultVal)
+		}
+	}
+
+	testBattery := []struct {
+		name string
+		test func(t *testing.T)
+	}{
+		{
+			name: "TestWithoutTag",
+			test: func(t *testing.T) {
+				checkReturn(t, "", false, &struct {
+					I int
+				}{})
+			},
+		},
+		{
+			name: "TestWithValue",
+			test: func(t *testing.T) {
+				checkReturn(t, "5432", true, &struct {
+					I int `default:"  5432 "`
+				}{})
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, pair.test)
+	}
+}
+
+func TestInvalidTagKeyDefaultParsing(t *testing.T) {
+	sf := reflect.TypeOf(&struct {
+		I int `default:"   "`
+	}{}).Elem().Field(0)
+
+	defaultVal, ok, err := parseTagKeyDefault(&sf)
+	if _, isInvalid := err.(*InvalidTagKeyValueError); !isInvalid {
+		t.Errorf("Expecting err InvalidTagKeyValueError, got %v", err)
+	}
+
+	if ok || defaultVal != "" {
+		t.Errorf("Expecting missing default on error, got %v", defaultVal)
+	}
+}
+
+func TestTagKeySepParsing(t *testing.T) {
+	sf := reflect.TypeOf(&struct {
+		S []string `sep:" ; "`
+	}{}).Elem().Field(0)
+
+	if sep, err := parseTagKeySep(&sf); err != nil || sep != ";" {
+		t.Errorf("Expecting separator ; and nil err, got %v and %v", sep, err)
+	}
+
+	sf = reflect.TypeOf(&struct {
+		S []string `sep:""`
+	}{}).Elem().Field(0)
+
+	if _, err := parseTagKeySep(&sf); err == nil {
+		t.Error("Expecting err InvalidTagKeyValueError, got nil")
+	} else if _, ok := err.(*InvalidTagKeyValueError); !ok {
+		t.Errorf("Expecting err InvalidTagKeyValueError, got %v", err)
+	}
+}
+
+func TestTagKeySecretParsing(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		varName  string
+		srtPtr   any
+		expected bool
+	}{
+		{"TestConvention", "A_SECRET", &struct{ S string }{}, true},
+		{"TestWithoutConvention", "A_SECRETS", &struct{ S string }{}, false},
+		{"TestTagOverridesConvention", "A_SECRET", &struct {
+			S string `secret:"no"`
+		}{}, false},
+		{"TestTag", "A", &struct {
+			S string `secret:"yes"`
+		}{}, true},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			sf := reflect.TypeOf(pair.srtPtr).Elem().Field(0)
+
+			secret, err := parseTagKeySecret(&sf, pair.varName)
+			if err != nil {
+				t.Errorf("Expecting nil err, got %v", err)
+			}
+
+			if secret != pair.expected {
+				t.Errorf("Expecting secret to be %v in tag %v", pair.expected, sf.Tag)
+			}
+		})
+	}
+
+	sf := reflect.TypeOf(&struct {
+		S string `secret:"maybe"`
+	}{}).Elem().Field(0)
+
+	if _, err := parseTagKeySecret(&sf, "A"); err == nil {
+		t.Error("Expecting err InvalidTagKeyValueError, got nil")
+	}
+}
+
+func TestTagKeyReloadParsing(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		srtPtr   any
+		expected bool
+	}{
+		{"TestMissing", &struct{ S string }{}, true},
+		{"TestReloadable", &struct {
+			S string `reload:"yes"`
+		}{}, true},
+		{"TestNonReloadable", &struct {
+			S string `reload:"no"`
+		}{}, false},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			sf := reflect.TypeOf(pair.srtPtr).Elem().Field(0)
+
+			reload, err := parseTagKeyReload(&sf)
+			if err != nil {
+				t.Errorf("Expecting nil err, got %v", err)
+			}
+
+			if reload != pair.expected {
+				t.Errorf("Expecting reload to be %v in tag %v", pair.expected, sf.Tag)
+			}
+		})
+	}
+}
+
+func TestTagKeyRequiredIfParsing(t *testing.T) {
+	sf := reflect.TypeOf(&struct {
+		S string `required_if:"A=1, B = yes"`
+	}{}).Elem().Field(0)
+
+	conditions, err := parseTagKeyRequiredIf(&sf)
+	if err != nil {
+		t.Errorf("Expecting nil err, got %v", err)
+		return
+	}
+
+	if len(conditions) != 2 ||
+		conditions[0].name != "A" || conditions[0].rawValue != "1" ||
+		conditions[1].name != "B" || conditions[1].rawValue != "yes" {
+		t.Errorf("Unexpected conditions in tag %v", sf.Tag)
+	}
+
+	for _, tag := range []string{"A", "A=", "=1", "A=1,", "1A=1"} {
+		sf := reflect.StructField{Name: "S", Tag: reflect.StructTag(`required_if:"` + tag + `"`)}
+
+		if _, err := parseTagKeyRequiredIf(&sf); err == nil {
+			t.Errorf("Expecting err InvalidTagKeyValueFmtError in tag %v, got nil", sf.Tag)
+		}
+	}
+}
+
+func TestTagKeyVarNamesParsing(t *testing.T) {
+	sf := reflect.TypeOf(&struct {
+		S string `excludes:"A, B"`
+	}{}).Elem().Field(0)
+
+	names, err := parseTagKeyVarNames(&sf, excludesTagKey)
+	if err != nil || !reflect.DeepEqual(names, []string{"A", "B"}) {
+		t.Errorf("Expecting names [A B], got %v (err %v)", names, err)
+	}
+
+	for _, tag := range []string{"", "A,", "A,,B", "A-B"} {
+		sf := reflect.StructField{Name: "S", Tag: reflect.StructTag(`excludes:"` + tag + `"`)}
+
+		if _, err := parseTagKeyVarNames(&sf, excludesTagKey); err == nil {
+			t.Errorf("Expecting err InvalidTagKeyValueFmtError in tag %v, got nil", sf.Tag)
+		}
+	}
+}
@@ -17,10 +17,9 @@ limitations under the License.
 package config
 
 import (
-	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/config/gen"
 	"reflect"
-	"strconv"
-	"time"
 )
 
 // typeConverter represents the function that will try to convert the raw
@@ -28,99 +27,225 @@ import (
 // by vRep. vRep must be a value of a settable struct field
 type typeConverter = func(vRep *reflect.Value, rawVal string) error
 
-// parseString sets the raw value itself
-func parseString(vRep *reflect.Value, rawVal string) error {
-	vRep.SetString(rawVal)
+// newConverter returns a converter that sets the value obtained by
+// parse, which is only assigned to the field if it's valid
+func newConverter[T any](parse func(rawVal string) (T, error)) typeConverter {
+	return func(vRep *reflect.Value, rawVal string) error {
+		val, err := parse(rawVal)
+		if err == nil {
+			vRep.Set(reflect.ValueOf(&val).Elem())
+		}
 
-	return nil
+		return err
+	}
 }
 
-// parseInt tries to obtain an int from the given
-// raw value and set it to the corresponding field
-func parseInt(vRep *reflect.Value, rawVal string) error {
-	val, err := strconv.Atoi(rawVal)
-	if err == nil {
-		vRep.SetInt(int64(val))
-	}
+// Converters of the builtin types
+var (
+	parseString        = newConverter(gen.ParseString)
+	parseInt           = newConverter(gen.ParseSigned[int])
+	parseInt8          = newConverter(gen.ParseSigned[int8])
+	parseInt16         = newConverter(gen.ParseSigned[int16])
+	parseInt32         = newConverter(gen.ParseSigned[int32])
+	parseInt64         = newConverter(gen.ParseSigned[int64])
+	parseUnsignedInt   = newConverter(gen.ParseUnsigned[uint])
+	parseUnsignedInt8  = newConverter(gen.ParseUnsigned[uint8])
+	parseUnsignedInt16 = newConverter(gen.ParseUnsigned[uint16])
+	parseUnsignedInt32 = newConverter(gen.ParseUnsigned[uint32])
+	parseUnsignedInt64 = newConverter(gen.ParseUnsigned[uint64])
+	parseFloat32       = newConverter(gen.ParseFloat[float32])
+	parseFloat64       = newConverter(gen.ParseFloat[float64])
+	parseDuration      = newConverter(gen.ParseDuration)
+	parseBool          = newConverter(gen.ParseBool)
+	parseAddrsRef      = newConverter(gen.ParseAddrs)
+	parseByteSize      = newConverter(gen.ParseByteSize)
+	parseURL           = newConverter(gen.ParseURL)
+	parseAddr          = newConverter(gen.ParseAddr)
+	parsePrefix        = newConverter(gen.ParsePrefix)
+	parseLogLevel      = newConverter(gen.ParseLogLevel)
+	parseLocationRef   = newConverter(gen.ParseLocation)
+	parseTime          = newConverter(gen.ParseTime)
+)
+
+// Collection separators
+const (
+	// defaultSeparator splits the elements of a collection
+	defaultSeparator = ","
+	// keyValueSeparator splits the key from the value of a map entry
+	keyValueSeparator = "="
+)
+
+// splitElements splits a raw collection value with sep. Returns
+// InvalidCollectionFmtError if the value has some empty element
+func splitElements(rawVal, sep string) ([]string, error) {
+	elems, err := gen.SplitElements(rawVal, sep)
 
-	return err
+	return elems, fromGenerated(err)
 }
 
-// parseInt32 tries to obtain an int32 from the given
-// raw value and set it to the corresponding field
-func parseInt32(vRep *reflect.Value, rawVal string) error {
-	val, err := strconv.ParseInt(rawVal, 10, 32)
-	if err == nil {
-		vRep.SetInt(val)
-	}
+// splitEntry splits a map entry in the format key=value. Returns
+// InvalidCollectionFmtError if the key or the value are missing
+func splitEntry(rawEntry string) (string, string, error) {
+	key, val, err := gen.SplitEntry(rawEntry)
+
+	return key, val, fromGenerated(err)
+}
 
-	return err
+// newSliceConverter returns a converter that splits the raw
+// value with sep, converting each element with elemConverter
+func newSliceConverter(elemConverter typeConverter, sep string) typeConverter {
+	return func(vRep *reflect.Value, rawVal string) error {
+		elems, err := splitElements(rawVal, sep)
+		if err != nil {
+			return err
+		}
+
+		slice := reflect.MakeSlice(vRep.Type(), len(elems), len(elems))
+
+		for i, elem := range elems {
+			elemRep := slice.Index(i)
+			if err := elemConverter(&elemRep, elem); err != nil {
+				return err
+			}
+		}
+
+		vRep.Set(slice)
+
+		return nil
+	}
 }
 
-// parseUnsignedInt16 tries to obtain an uint16 from the
-// given raw value and set it to the corresponding field
-func parseUnsignedInt16(vRep *reflect.Value, rawVal string) error {
-	val, err := strconv.ParseUint(rawVal, 10, 16)
-	if err == nil {
-		vRep.SetUint(val)
+// newMapConverter returns a converter that splits the raw value with sep,
+// where each entry is in the format key=value. Each entry value is converted
+// with elemConverter. Returns InvalidCollectionFmtError if a key is repeated
+func newMapConverter(elemConverter typeConverter, sep string) typeConverter {
+	return func(vRep *reflect.Value, rawVal string) error {
+		entries, err := splitElements(rawVal, sep)
+		if err != nil {
+			return err
+		}
+
+		mapT := vRep.Type()
+		newMap := reflect.MakeMapWithSize(mapT, len(entries))
+
+		for _, entry := range entries {
+			key, val, err := splitEntry(entry)
+			if err != nil {
+				return err
+			}
+
+			keyRep := reflect.ValueOf(key).Convert(mapT.Key())
+			if newMap.MapIndex(keyRep).IsValid() {
+				return &InvalidCollectionFmtError{
+					rawValue: rawVal,
+					reason:   fmt.Sprintf("repeated key %v", key),
+				}
+			}
+
+			elemRep := reflect.New(mapT.Elem()).Elem()
+			if err := elemConverter(&elemRep, val); err != nil {
+				return err
+			}
+
+			newMap.SetMapIndex(keyRep, elemRep)
+		}
+
+		vRep.Set(newMap)
+
+		return nil
 	}
+}
+
+// isCollection tells if a given type is a slice or a map
+// that doesn't have a converter for the type itself
+func isCollection(t reflect.Type) bool {
+	kind := t.Kind()
 
-	return err
+	return (kind == reflect.Slice || kind == reflect.Map) &&
+		selectScalarConverter(t) == nil
 }
 
-// parseDuration tries to obtain a valid one from the
-// given raw value and set it to the corresponding field
-func parseDuration(vRep *reflect.Value, rawVal string) error {
-	val, err := time.ParseDuration(rawVal)
-	if err == nil {
-		vRep.Set(reflect.ValueOf(val))
+// elemType returns the type of each element (or entry value)
+// if t is a collection. Otherwise, returns t itself
+func elemType(t reflect.Type) reflect.Type {
+	if isCollection(t) {
+		return t.Elem()
 	}
 
-	return err
+	return t
 }
 
-// parseBool tries to obtain a bool from the given
-// raw value and set it to the corresponding field
-func parseBool(vRep *reflect.Value, rawVal string) error {
-	val, err := strconv.ParseBool(rawVal)
-	if err == nil {
-		vRep.SetBool(val)
+// newOptionalConverter returns a converter of pointers to scalars, which
+// allocates a new value converted by elemConverter. Therefore, the field
+// is only assigned (i.e. isn't nil) if the variable is provided
+func newOptionalConverter(elemConverter typeConverter) typeConverter {
+	return func(vRep *reflect.Value, rawVal string) error {
+		ptr := reflect.New(vRep.Type().Elem())
+
+		elemRep := ptr.Elem()
+		if err := elemConverter(&elemRep, rawVal); err != nil {
+			return err
+		}
+
+		vRep.Set(ptr)
+
+		return nil
 	}
+}
 
-	return err
+// isOptional tells if t is a pointer to a type that has a converter.
+// Pointers with their own converter (e.g. *utils.Addrs) aren't optionals
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Pointer &&
+		lookupConverter(t) == nil && selectUnmarshalerConverter(t) == nil &&
+		t.Elem().Kind() != reflect.Pointer && selectScalarConverter(t.Elem()) != nil
 }
 
-// parseAddrsRef tries to obtain a *utils.Addrs from the
-// given raw value and set it to the corresponding field
-func parseAddrsRef(vRep *reflect.Value, rawVal string) error {
-	val, err := utils.ParseAddrs(rawVal)
-	if err == nil {
-		vRep.Set(reflect.ValueOf(val))
+// selectScalarConverter returns a converter if the type is registered or
+// implements Unmarshaler or encoding.TextUnmarshaler (by this order).
+// Otherwise, if t is a pointer to one of them, returns an optional converter
+func selectScalarConverter(t reflect.Type) typeConverter {
+	if converter := lookupConverter(t); converter != nil {
+		return converter
 	}
 
-	return err
+	if converter := selectUnmarshalerConverter(t); converter != nil {
+		return converter
+	}
+
+	if isOptional(t) {
+		return newOptionalConverter(selectScalarConverter(t.Elem()))
+	}
+
+	return nil
 }
 
-// selectConverter returns a converter if field type is supported
-func selectConverter(field *reflect.Value) typeConverter {
-	inter := field.Interface()
-
-	switch inter.(type) {
-	case string:
-		return parseString
-	case int:
-		return parseInt
-	case int32:
-		return parseInt32
-	case uint16:
-		return parseUnsignedInt16
-	case time.Duration:
-		return parseDuration
-	case bool:
-		return parseBool
-	case *utils.Addrs:
-		return parseAddrsRef
-	default:
+// selectElemConverter returns the converter of each element if fieldT is a
+// collection, otherwise returns the converter of the field itself. Only slices
+// and maps with string keys are supported, as long as their elements are scalars
+func selectElemConverter(fieldT reflect.Type) typeConverter {
+	if !isCollection(fieldT) {
+		return selectScalarConverter(fieldT)
+	}
+
+	if fieldT.Kind() == reflect.Map && fieldT.Key().Kind() != reflect.String {
 		return nil
 	}
+
+	return selectScalarConverter(fieldT.Elem())
+}
+
+// selectConverter returns a converter if fieldT is
+// supported. Collections elements are separated by sep
+func selectConverter(fieldT reflect.Type, sep string) typeConverter {
+	elemConverter := selectElemConverter(fieldT)
+	if elemConverter == nil || !isCollection(fieldT) {
+		return elemConverter
+	}
+
+	if fieldT.Kind() == reflect.Slice {
+		return newSliceConverter(elemConverter, sep)
+	}
+
+	return newMapConverter(elemConverter, sep)
 }
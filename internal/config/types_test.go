@@ -18,8 +18,13 @@ package config
 
 import (
 	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
 	"github.com/franciscosbf/micro-dwarf/internal/utils"
+	"log/slog"
 	"math"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -191,3 +196,424 @@ func TestInvalidAddrsRefParsing(t *testing.T) {
 		t.Error("Expecting getting an error")
 	}
 }
+
+func TestValidSliceParsing(t *testing.T) {
+	var ds []time.Duration
+
+	v := reflect.ValueOf(&ds).Elem()
+	converter := newSliceConverter(parseDuration, ";")
+	if err := converter(&v, " 1s ; 2m;3h"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Minute, 3 * time.Hour}
+
+	if len(ds) != len(expected) {
+		t.Errorf("Expecting assign value %v, got: %v", expected, ds)
+		return
+	}
+
+	for i, d := range expected {
+		if ds[i] != d {
+			t.Errorf("Expecting %v at position %v, got: %v", d, i, ds[i])
+		}
+	}
+}
+
+func TestInvalidSliceParsing(t *testing.T) {
+	var is []int
+
+	converter := newSliceConverter(parseInt, ",")
+
+	v := reflect.ValueOf(&is).Elem()
+	if err := converter(&v, "1,,2"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+
+	if err := converter(&v, "1,a"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidMapParsing(t *testing.T) {
+	var m map[string]string
+
+	v := reflect.ValueOf(&m).Elem()
+	converter := newMapConverter(parseString, ",")
+	if err := converter(&v, "users = 1s, friends=2s"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if len(m) != 2 || m["users"] != "1s" || m["friends"] != "2s" {
+		t.Errorf("Expecting assign value map[friends:2s users:1s], got: %v", m)
+	}
+}
+
+func TestInvalidMapParsing(t *testing.T) {
+	var m map[string]string
+
+	converter := newMapConverter(parseString, ",")
+
+	v := reflect.ValueOf(&m).Elem()
+	for _, raw := range []string{"a=1,b", "a=1,=2", "a=1,b=", "a=1,a=2"} {
+		if err := converter(&v, raw); err == nil {
+			t.Errorf("Expecting getting an error with value %v", raw)
+		}
+	}
+}
+
+func TestCollectionConverterSelection(t *testing.T) {
+	supported := []any{
+		&[]string{}, &[]int{}, &[]time.Duration{}, &map[string]string{}, &map[string]int{},
+	}
+
+	for _, ptr := range supported {
+		v := reflect.ValueOf(ptr).Elem()
+		if selectConverter(v.Type(), defaultSeparator) == nil {
+			t.Errorf("Expecting converter for type %v", v.Type())
+		}
+	}
+
+	unsupported := []any{
+		&[]struct{}{}, &map[int]string{}, &map[string]struct{}{},
+	}
+
+	for _, ptr := range unsupported {
+		v := reflect.ValueOf(ptr).Elem()
+		if selectConverter(v.Type(), defaultSeparator) != nil {
+			t.Errorf("Expecting nil converter for type %v", v.Type())
+		}
+	}
+}
+
+func TestSizedNumbersParsing(t *testing.T) {
+	testBattery := []struct {
+		name     string
+		fieldPtr any
+		valid    string
+		expected any
+		invalid  string
+	}{
+		{"TestInt8", new(int8), "-128", int8(math.MinInt8), "128"},
+		{"TestInt16", new(int16), "32767", int16(math.MaxInt16), "32768"},
+		{"TestInt64", new(int64), "-9223372036854775808", int64(math.MinInt64), "9223372036854775808"},
+		{"TestUint", new(uint), "18446744073709551615", uint(math.MaxUint), "-1"},
+		{"TestUint8", new(uint8), "255", uint8(math.MaxUint8), "256"},
+		{"TestUint32", new(uint32), "4294967295", uint32(math.MaxUint32), "4294967296"},
+		{"TestUint64", new(uint64), "18446744073709551615", uint64(math.MaxUint64), "18446744073709551616"},
+		{"TestFloat32", new(float32), "1.5", float32(1.5), "1e39"},
+		{"TestFloat64", new(float64), "-2.25e10", -2.25e10, "bark"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			v := reflect.ValueOf(pair.fieldPtr).Elem()
+
+			converter := selectScalarConverter(v.Type())
+			if converter == nil {
+				t.Errorf("Expecting converter of type %v", v.Type())
+				return
+			}
+
+			if err := converter(&v, pair.valid); err != nil {
+				t.Errorf("Unexptected error %v", err)
+			}
+
+			if v.Interface() != pair.expected {
+				t.Errorf("Expecting assign value %v, got: %v", pair.expected, v.Interface())
+			}
+
+			if err := converter(&v, pair.invalid); err == nil {
+				t.Errorf("Expecting getting an error with value %v", pair.invalid)
+			}
+		})
+	}
+}
+
+func TestOptionalParsing(t *testing.T) {
+	var i *int
+
+	v := reflect.ValueOf(&i).Elem()
+
+	converter := selectScalarConverter(v.Type())
+	if converter == nil {
+		t.Error("Expecting converter of type *int")
+		return
+	}
+
+	if err := converter(&v, "bark"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+
+	if i != nil {
+		t.Errorf("Expecting nil pointer, got: %v", *i)
+	}
+
+	if err := converter(&v, "0"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if i == nil || *i != 0 {
+		t.Errorf("Expecting pointer to 0, got: %v", i)
+	}
+}
+
+func TestUnsupportedOptional(t *testing.T) {
+	for _, fieldPtr := range []any{new(**int), new(*[]int), new(*struct{})} {
+		fieldT := reflect.TypeOf(fieldPtr).Elem()
+
+		if isOptional(fieldT) || selectScalarConverter(fieldT) != nil {
+			t.Errorf("Expecting type %v to be unsupported", fieldT)
+		}
+	}
+
+	if isOptional(reflect.TypeOf((*utils.Addrs)(nil))) {
+		t.Error("Expecting *utils.Addrs to have its own converter")
+	}
+}
+
+func TestValidByteSizeParsing(t *testing.T) {
+	var s utils.ByteSize
+
+	v := reflect.ValueOf(&s).Elem()
+	if err := parseByteSize(&v, "64MiB"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if s != 64*utils.MiB {
+		t.Errorf("Expecting assign value \"64MiB\", got: %v", s)
+	}
+}
+
+func TestInvalidByteSizeParsing(t *testing.T) {
+	var s utils.ByteSize
+
+	v := reflect.ValueOf(&s).Elem()
+	if err := parseByteSize(&v, "64 potatoes"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidURLParsing(t *testing.T) {
+	var u url.URL
+
+	v := reflect.ValueOf(&u).Elem()
+	if err := parseURL(&v, "https://user@localhost:8080/path?q=1"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if u.Scheme != "https" || u.Host != "localhost:8080" || u.Path != "/path" {
+		t.Errorf("Expecting assign value \"https://user@localhost:8080/path?q=1\", got: %v", &u)
+	}
+}
+
+func TestInvalidURLParsing(t *testing.T) {
+	var u url.URL
+
+	v := reflect.ValueOf(&u).Elem()
+	for _, raw := range []string{"/relative/path", "http://[::1"} {
+		if err := parseURL(&v, raw); err == nil {
+			t.Errorf("Expecting getting an error with %v", raw)
+		}
+	}
+}
+
+func TestValidAddrParsing(t *testing.T) {
+	var a netip.Addr
+
+	v := reflect.ValueOf(&a).Elem()
+	if err := parseAddr(&v, "::1"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if a != netip.IPv6Loopback() {
+		t.Errorf("Expecting assign value \"::1\", got: %v", a)
+	}
+}
+
+func TestInvalidAddrParsing(t *testing.T) {
+	var a netip.Addr
+
+	v := reflect.ValueOf(&a).Elem()
+	if err := parseAddr(&v, "256.0.0.1"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidPrefixParsing(t *testing.T) {
+	var p netip.Prefix
+
+	v := reflect.ValueOf(&p).Elem()
+	if err := parsePrefix(&v, "10.0.0.0/8"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if p != netip.MustParsePrefix("10.0.0.0/8") {
+		t.Errorf("Expecting assign value \"10.0.0.0/8\", got: %v", p)
+	}
+}
+
+func TestInvalidPrefixParsing(t *testing.T) {
+	var p netip.Prefix
+
+	v := reflect.ValueOf(&p).Elem()
+	if err := parsePrefix(&v, "10.0.0.0/33"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidLogLevelParsing(t *testing.T) {
+	var l slog.Level
+
+	v := reflect.ValueOf(&l).Elem()
+	if err := parseLogLevel(&v, "warn"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if l != slog.LevelWarn {
+		t.Errorf("Expecting assign value \"warn\", got: %v", l)
+	}
+}
+
+func TestInvalidLogLevelParsing(t *testing.T) {
+	var l slog.Level
+
+	v := reflect.ValueOf(&l).Elem()
+	if err := parseLogLevel(&v, "verbose"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidLocationRefParsing(t *testing.T) {
+	var l *time.Location
+
+	v := reflect.ValueOf(&l).Elem()
+	if err := parseLocationRef(&v, "UTC"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	if l != time.UTC {
+		t.Errorf("Expecting assign value \"UTC\", got: %v", l)
+	}
+}
+
+func TestInvalidLocationRefParsing(t *testing.T) {
+	var l *time.Location
+
+	v := reflect.ValueOf(&l).Elem()
+	if err := parseLocationRef(&v, "Nowhere/Somewhere"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestValidTimeParsing(t *testing.T) {
+	var tm time.Time
+
+	v := reflect.ValueOf(&tm).Elem()
+	if err := parseTime(&v, "2023-05-01T10:00:00+01:00"); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	expected := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+	if !tm.Equal(expected) {
+		t.Errorf("Expecting assign value \"%v\", got: %v", expected, tm)
+	}
+}
+
+func TestInvalidTimeParsing(t *testing.T) {
+	var tm time.Time
+
+	v := reflect.ValueOf(&tm).Elem()
+	if err := parseTime(&v, "2023-05-01 10:00:00"); err == nil {
+		t.Error("Expecting getting an error")
+	}
+}
+
+func TestRichTypesParseConf(t *testing.T) {
+	type Dummy struct {
+		Size     utils.ByteSize `name:"RICH_SIZE" min:"1KiB" max:"1GiB"`
+		Endpoint *url.URL       `name:"RICH_ENDPOINT" pattern:"^https://"`
+		Addr     netip.Addr     `name:"RICH_ADDR" min:"10.0.0.0" max:"10.255.255.255"`
+		Networks []netip.Prefix `name:"RICH_NETWORKS"`
+		Level    slog.Level     `name:"RICH_LEVEL" accepts:"DEBUG,INFO" default:"INFO"`
+		Location *time.Location `name:"RICH_LOCATION"`
+		Since    time.Time      `name:"RICH_SINCE" min:"2020-01-01T00:00:00Z"`
+	}
+
+	testBattery := []struct {
+		name     string
+		vars     map[string]string
+		expected []errorw.ErrorCode
+	}{
+		{
+			name: "TestValidValues",
+			vars: map[string]string{
+				"RICH_SIZE":     "64MiB",
+				"RICH_ENDPOINT": "https://localhost",
+				"RICH_ADDR":     "10.0.0.1",
+				"RICH_NETWORKS": "10.0.0.0/8,192.168.0.0/16",
+				"RICH_LEVEL":    "DEBUG",
+				"RICH_LOCATION": "UTC",
+				"RICH_SINCE":    "2023-05-01T10:00:00Z",
+			},
+		},
+		{
+			name: "TestInvalidValues",
+			vars: map[string]string{
+				"RICH_SIZE":     "2GiB",
+				"RICH_ENDPOINT": "http://localhost",
+				"RICH_ADDR":     "192.168.0.1",
+				"RICH_NETWORKS": "10.0.0.0",
+				"RICH_LEVEL":    "WARN",
+				"RICH_SINCE":    "2019-05-01T10:00:00Z",
+			},
+			expected: []errorw.ErrorCode{
+				ErrorCodeConstraintViolation, ErrorCodeConstraintViolation,
+				ErrorCodeConstraintViolation, ErrorCodeInvalidVarType,
+				ErrorCodeUnacceptedVal, ErrorCodeConstraintViolation,
+			},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			for name, value := range pair.vars {
+				t.Setenv(name, value)
+			}
+
+			cp, _ := New(envvars.New(&FakeProvider{}))
+
+			d := &Dummy{}
+			err := cp.ParseConf(d)
+
+			if pair.expected == nil {
+				if err != nil {
+					t.Errorf("Unexpected error from config parser: %v", err)
+				} else if d.Size != 64*utils.MiB || d.Endpoint.Host != "localhost" ||
+					len(d.Networks) != 2 || d.Level != slog.LevelDebug || d.Location != time.UTC {
+					t.Errorf("Unexpected parsed values: %+v", d)
+				}
+
+				return
+			}
+
+			vErr, ok := err.(*VariablesError)
+			if !ok {
+				t.Errorf("Expecting error of type VariablesError, got %v", err)
+				return
+			}
+
+			errs := vErr.Errors()
+			if len(errs) != len(pair.expected) {
+				t.Errorf("Expecting %v aggregated errors, got %v", len(pair.expected), vErr)
+				return
+			}
+
+			for i, code := range pair.expected {
+				if err, ok := errs[i].(*errorw.Wrapper); !ok || err.Code() != code {
+					t.Errorf("Expecting error with code %v at position %v, got %v", code, i, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FieldChange describes a variable whose value has changed on reload.
+// Values are formatted like in Dump, i.e. secrets are redacted
+type FieldChange struct {
+	Name      string // Variable's name, including the namespace
+	FieldPath string // Struct field path, e.g. Tls.Cert
+	Old       string // Previous value
+	New       string // Current value
+}
+
+// Subscriber is notified with the reloaded config and the changes that were
+// applied to it. The config must be treated as read-only, since it's shared
+// between subscribers and returned by Watcher.Current
+type Subscriber[T any] func(conf *T, changes []*FieldChange) error
+
+// snapshot is a parsed config along with where its values came from
+type snapshot[T any] struct {
+	conf    *T
+	origins []*valueOrigin
+}
+
+// Watcher keeps a config struct of type T up to date, by parsing
+// it again on each reload. Changes of variables tagged with
+// reload:"no" are reported as warnings and never applied
+type Watcher[T any] struct {
+	parser      *ConfParser
+	current     atomic.Pointer[snapshot[T]]
+	reloadMutex sync.Mutex
+
+	mutex       sync.RWMutex
+	subscribers []Subscriber[T]
+	onWarning   func(err error)
+	onError     func(err error)
+}
+
+// logHandler is the default handler of warnings and errors
+func logHandler(err error) {
+	log.Printf("config watcher: %v", err)
+}
+
+// discardHandler replaces nil handlers
+func discardHandler(error) {}
+
+// Subscribe registers a subscriber that will be
+// notified on each reload that changes the config
+func (w *Watcher[T]) Subscribe(subscriber Subscriber[T]) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.subscribers = append(w.subscribers, subscriber)
+}
+
+// OnWarning replaces the handler of ignored changes, which receives
+// UnreloadableChangeError. By default, warnings are logged. If
+// handler is nil, warnings are discarded
+func (w *Watcher[T]) OnWarning(handler func(err error)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if handler == nil {
+		handler = discardHandler
+	}
+
+	w.onWarning = handler
+}
+
+// OnError replaces the handler of errors returned by reloads
+// triggered in Watch. By default, errors are logged. If
+// handler is nil, errors are discarded
+func (w *Watcher[T]) OnError(handler func(err error)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if handler == nil {
+		handler = discardHandler
+	}
+
+	w.onError = handler
+}
+
+// Current returns the last successfully parsed config. It must be
+// treated as read-only, since a reload replaces it instead of changing it
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load().conf
+}
+
+// Explain describes where the value of each variable of the
+// current config came from, see ConfParser.Explain
+func (w *Watcher[T]) Explain() []*Provenance {
+	current := w.current.Load()
+
+	srtVal, _ := extractStrVal(current.conf)
+
+	// It was already parsed, so it's safe to ignore errors
+	variables, _ := cachedFields(srtVal.Type())
+
+	return explain(srtVal, variables, current.origins)
+}
+
+// diff compares the variables of both configs, parsed by cp, returning
+// the changes that can be applied. Changes of variables that can't be
+// reloaded are reverted in next, along with their origins, and returned
+// as UnreloadableChangeError
+func diff[T any](cp *ConfParser, prev, next *snapshot[T]) ([]*FieldChange, []error) {
+	prevVal, _ := extractStrVal(prev.conf)
+	nextVal, _ := extractStrVal(next.conf)
+
+	// Both were already parsed, so it's safe to ignore errors
+	variables, _ := cachedFields(nextVal.Type())
+
+	var changes []*FieldChange
+	var warnings []error
+
+	for i, v := range variables {
+		prevField, nextField := v.fieldOf(prevVal), v.fieldOf(nextVal)
+
+		if reflect.DeepEqual(prevField.Interface(), nextField.Interface()) {
+			continue
+		}
+
+		if !v.reloadable {
+			nextField.Set(prevField)
+			next.origins[i] = prev.origins[i]
+			warnings = append(warnings, &UnreloadableChangeError{
+				varName:   cp.varName(v.name),
+				fieldPath: v.fieldPath,
+			})
+
+			continue
+		}
+
+		changes = append(changes, &FieldChange{
+			Name:      cp.varName(v.name),
+			FieldPath: v.fieldPath,
+			Old:       dumpValue(v, prevVal),
+			New:       dumpValue(v, nextVal),
+		})
+	}
+
+	return changes, warnings
+}
+
+// Reload parses the config again and compares it against the current
+// one. If some variable has changed, the new config replaces the current
+// and subscribers are notified. Changes that can't be reloaded are passed
+// to the warnings handler. If the config is invalid, the current one is
+// kept and the error is returned as ConfParser.ParseConf does. Otherwise,
+// returns the errors of subscribers (if any) joined with errors.Join
+func (w *Watcher[T]) Reload() error {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+
+	next := &snapshot[T]{conf: new(T)}
+
+	var err error
+	if next.origins, err = w.parser.parseConf(next.conf); err != nil {
+		return err
+	}
+
+	changes, warnings := diff(w.parser, w.current.Load(), next)
+
+	w.mutex.RLock()
+	onWarning := w.onWarning
+	subscribers := w.subscribers
+	w.mutex.RUnlock()
+
+	for _, warning := range warnings {
+		onWarning(warning)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	w.current.Store(next)
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		if err := subscriber(next.conf, changes); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Watch reloads the config at each interval and whenever one of the
+// given signals is received (e.g. syscall.SIGHUP), until ctx is done.
+// Intervals equal or lower than zero are ignored. Errors are passed to
+// the errors handler. Returns WithoutWatchTriggersError if there isn't
+// neither an interval nor signals, otherwise blocks and returns nil
+func (w *Watcher[T]) Watch(ctx context.Context, interval time.Duration, signals ...os.Signal) error {
+	if interval <= 0 && len(signals) == 0 {
+		return WithoutWatchTriggersError
+	}
+
+	var ticks <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	received := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(received, signals...)
+		defer signal.Stop(received)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticks:
+		case <-received:
+		}
+
+		if err := w.Reload(); err != nil {
+			w.mutex.RLock()
+			onError := w.onError
+			w.mutex.RUnlock()
+
+			onError(err)
+		}
+	}
+}
+
+// NewWatcher parses a config struct of type T with parser, returning
+// a watcher that holds it. Returns the same errors as ConfParser.ParseConf
+func NewWatcher[T any](parser *ConfParser) (*Watcher[T], error) {
+	if parser == nil {
+		return nil, MissingConfParserError
+	}
+
+	current := &snapshot[T]{conf: new(T)}
+
+	var err error
+	if current.origins, err = parser.parseConf(current.conf); err != nil {
+		return nil, err
+	}
+
+	w := &Watcher[T]{
+		parser:    parser,
+		onWarning: logHandler,
+		onError:   logHandler,
+	}
+	w.current.Store(current)
+
+	return w, nil
+}
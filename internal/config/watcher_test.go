@@ -0,0 +1,279 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type watchedDummy struct {
+	Host     string        `name:"WATCH_HOST" reload:"no"`
+	Password string        `name:"WATCH_PASSWORD_SECRET"`
+	Timeout  time.Duration `name:"WATCH_TIMEOUT" default:"1s"`
+	Nested   struct {
+		Size int `name:"SIZE"`
+	} `prefix:"WATCH_NESTED_" reload:"no"`
+}
+
+var watchedVars = []string{
+	"WATCH_HOST", "WATCH_PASSWORD_SECRET", "WATCH_TIMEOUT", "WATCH_NESTED_SIZE"}
+
+func newWatchedDummy(t *testing.T) *Watcher[watchedDummy] {
+	parser, _ := New(envvars.New(&FakeProvider{}))
+
+	watcher, err := NewWatcher[watchedDummy](parser)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return watcher
+}
+
+func TestWatcherReload(t *testing.T) {
+	setVars(map[string]string{
+		"WATCH_HOST":            "localhost",
+		"WATCH_PASSWORD_SECRET": "pass",
+		"WATCH_NESTED_SIZE":     "1",
+	})
+	defer unsetVars(watchedVars...)
+
+	watcher := newWatchedDummy(t)
+	initial := watcher.Current()
+
+	var notified []*FieldChange
+	watcher.Subscribe(func(conf *watchedDummy, changes []*FieldChange) error {
+		if conf.Timeout != time.Minute {
+			t.Errorf("Expecting timeout %v, got %v", time.Minute, conf.Timeout)
+		}
+
+		notified = changes
+
+		return nil
+	})
+
+	var warnings []error
+	watcher.OnWarning(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	setVars(map[string]string{
+		"WATCH_HOST":            "remotehost",
+		"WATCH_PASSWORD_SECRET": "new",
+		"WATCH_TIMEOUT":         "1m",
+		"WATCH_NESTED_SIZE":     "2",
+	})
+
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := []FieldChange{
+		{"WATCH_PASSWORD_SECRET", "Password", RedactedValue, RedactedValue},
+		{"WATCH_TIMEOUT", "Timeout", "1s", "1m0s"},
+	}
+
+	if len(notified) != len(expected) {
+		t.Errorf("Expecting changes %v, got %v", expected, notified)
+		return
+	}
+
+	for i, change := range notified {
+		if *change != expected[i] {
+			t.Errorf("Expecting change %v, got %v", expected[i], *change)
+		}
+	}
+
+	if len(warnings) != 2 {
+		t.Errorf("Expecting 2 warnings, got %v", warnings)
+	}
+
+	for _, warning := range warnings {
+		var unreloadable *UnreloadableChangeError
+		if !errors.As(warning, &unreloadable) {
+			t.Errorf("Expecting UnreloadableChangeError, got %v", warning)
+		}
+	}
+
+	current := watcher.Current()
+	if current.Host != "localhost" || current.Nested.Size != 1 {
+		t.Errorf("Expecting unreloadable fields to be kept, got %+v", current)
+	}
+
+	if initial.Timeout != time.Second {
+		t.Errorf("Expecting previous config to be kept unchanged, got %+v", initial)
+	}
+}
+
+func TestWatcherReloadWithNamespace(t *testing.T) {
+	setVars(map[string]string{"NS_WATCH_HOST": "localhost"})
+	defer unsetVars("NS_WATCH_HOST", "NS_WATCH_TIMEOUT")
+
+	parser, _ := New(envvars.New(&FakeProvider{}), WithNamespace("NS"))
+
+	watcher, err := NewWatcher[watchedDummy](parser)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var notified []*FieldChange
+	watcher.Subscribe(func(_ *watchedDummy, changes []*FieldChange) error {
+		notified = changes
+
+		return nil
+	})
+
+	var warnings []error
+	watcher.OnWarning(func(err error) {
+		warnings = append(warnings, err)
+	})
+
+	setVars(map[string]string{"NS_WATCH_HOST": "remotehost", "NS_WATCH_TIMEOUT": "1m"})
+
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if len(notified) != 1 || notified[0].Name != "NS_WATCH_TIMEOUT" {
+		t.Errorf("Expecting change of NS_WATCH_TIMEOUT, got %v", notified)
+	}
+
+	expected := "variable NS_WATCH_HOST of struct field Host has changed but can't be reloaded"
+	if len(warnings) != 1 || warnings[0].Error() != expected {
+		t.Errorf("Expecting warning %v, got %v", expected, warnings)
+	}
+}
+
+func TestWatcherReloadWithoutChanges(t *testing.T) {
+	setVars(map[string]string{"WATCH_HOST": "localhost"})
+	defer unsetVars(watchedVars...)
+
+	watcher := newWatchedDummy(t)
+	initial := watcher.Current()
+
+	watcher.Subscribe(func(*watchedDummy, []*FieldChange) error {
+		t.Error("Unexpected notification")
+
+		return nil
+	})
+
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if watcher.Current() != initial {
+		t.Error("Expecting config to be kept")
+	}
+}
+
+func TestInvalidWatcherReload(t *testing.T) {
+	defer unsetVars(watchedVars...)
+
+	watcher := newWatchedDummy(t)
+	initial := watcher.Current()
+
+	setVars(map[string]string{"WATCH_TIMEOUT": "bark"})
+
+	var varsErr *VariablesError
+	if err := watcher.Reload(); !errors.As(err, &varsErr) {
+		t.Errorf("Expecting error VariablesError, got %v", err)
+	}
+
+	if watcher.Current() != initial {
+		t.Error("Expecting config to be kept")
+	}
+}
+
+func TestWatcherSubscriberErrors(t *testing.T) {
+	defer unsetVars(watchedVars...)
+
+	watcher := newWatchedDummy(t)
+
+	someErr := errors.New("some error")
+	watcher.Subscribe(func(*watchedDummy, []*FieldChange) error {
+		return someErr
+	})
+
+	setVars(map[string]string{"WATCH_TIMEOUT": "1m"})
+
+	if err := watcher.Reload(); !errors.Is(err, someErr) {
+		t.Errorf("Expecting error %v, got %v", someErr, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	defer unsetVars(watchedVars...)
+
+	watcher := newWatchedDummy(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := watcher.Watch(ctx, 0); err != WithoutWatchTriggersError {
+		t.Errorf("Expecting error WithoutWatchTriggersError, got %v", err)
+	}
+
+	reloaded := make(chan time.Duration, 1)
+	watcher.Subscribe(func(conf *watchedDummy, _ []*FieldChange) error {
+		reloaded <- conf.Timeout
+
+		return nil
+	})
+
+	// Otherwise, signals sent before Watch starts listening kill the process
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	done := make(chan error)
+	go func() {
+		done <- watcher.Watch(ctx, time.Hour, syscall.SIGUSR1)
+	}()
+
+	setVars(map[string]string{"WATCH_TIMEOUT": "1m"})
+
+	// Signal may arrive before Watch starts listening
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+
+		select {
+		case timeout := <-reloaded:
+			if timeout != time.Minute {
+				t.Errorf("Expecting timeout %v, got %v", time.Minute, timeout)
+			}
+			received = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Expecting reload after signal")
+		}
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -21,10 +21,14 @@ import (
 	"github.com/franciscosbf/micro-dwarf/internal/envvars"
 )
 
-// Read parses a given config struct. The returned
+// Read parses a given config struct, where opts configure
+// the parser, e.g. config.WithNamespace. The returned
 // error (if any) comes from the config parser
-func Read(vReader *envvars.VarReader, conf config.StructPtr) error {
-	parser, _ := config.New(vReader)
+func Read(vReader *envvars.VarReader, conf config.StructPtr, opts ...config.Option) error {
+	parser, err := config.New(vReader, opts...)
+	if err != nil {
+		return err
+	}
 
 	return parser.ParseConf(conf)
 }
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envvars
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var UnsupportedEnumerationError = errors.New("provider can't list its variables")
+
+// InterpolationCycleError represents a variable
+// that references itself, directly or not
+type InterpolationCycleError struct {
+	cycle []string
+}
+
+func (e *InterpolationCycleError) Error() string {
+	return fmt.Sprintf(
+		"variables reference each other: %v", strings.Join(e.cycle, " -> "))
+}
+
+// InvalidInterpolationError represents a bad formatted reference
+type InvalidInterpolationError struct {
+	rawValue string
+	reason   string
+}
+
+func (e *InvalidInterpolationError) Error() string {
+	return fmt.Sprintf("invalid reference in \"%v\": %v", e.rawValue, e.reason)
+}
+
+// fetchError represents an error returned by
+// the provider while fetching a referenced variable
+type fetchError struct {
+	key    string
+	origin error
+}
+
+func (e *fetchError) Error() string {
+	return fmt.Sprintf("couldn't get variable %v: %v", e.key, e.origin)
+}
@@ -16,9 +16,18 @@ limitations under the License.
 
 package envvars
 
-import "github.com/franciscosbf/micro-dwarf/internal/errorw"
+import (
+	"errors"
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"sort"
+)
 
-const ErrorCodeVarFetch errorw.ErrorCode = 0
+// Error codes
+const (
+	ErrorCodeVarFetch errorw.ErrorCode = iota
+	ErrorCodeInterpolation
+)
 
 // Provider represents the connector
 // that fetches variables. If key doesn't
@@ -28,25 +37,116 @@ type Provider interface {
 	Get(key string) (string, error)
 }
 
+// Enumerator is an optional capability of a Provider,
+// which lists the keys of its variables that start
+// with prefix. The order of the keys is irrelevant
+type Enumerator interface {
+	Keys(prefix string) ([]string, error)
+}
+
+// Describer is an optional capability of a Provider, which
+// describes where the variable key is defined, e.g. a file
+type Describer interface {
+	Describe(key string) string
+}
+
 // VarReader wraps the process of getting
 // variables with a given Provider
 type VarReader struct {
-	provider Provider
+	provider    Provider
+	interpolate bool
+}
+
+// Option configures a VarReader
+type Option func(vr *VarReader)
+
+// WithInterpolation expands references to other variables in the values
+// returned by Get, see VarReader.Get. It's disabled by default, since
+// values with $ (e.g. passwords) would change their meaning
+func WithInterpolation() Option {
+	return func(vr *VarReader) {
+		vr.interpolate = true
+	}
 }
 
 // New creates a new vars reader with a given provider
-func New(provider Provider) *VarReader {
-	return &VarReader{
+func New(provider Provider, opts ...Option) *VarReader {
+	vr := &VarReader{
 		provider: provider,
 	}
+
+	for _, opt := range opts {
+		opt(vr)
+	}
+
+	return vr
 }
 
-// Get returns a variable from the provider given its key
+// Get returns a variable from the provider given its key. If interpolation
+// is enabled, ${NAME} is replaced by the value of NAME, fetched from the same
+// provider, and ${NAME:-fallback} by fallback if NAME is empty. References
+// are expanded recursively, where $$ results in a literal $. Interpolation
+// errors, like InterpolationCycleError, are wrapped with ErrorCodeInterpolation
 func (vr *VarReader) Get(key string) (string, error) {
 	value, err := vr.provider.Get(key)
 	if err != nil {
 		return "", errorw.WrapErrorf(ErrorCodeVarFetch, err, "Couldn't get variable %v", key)
 	}
 
-	return value, nil
+	if !vr.interpolate {
+		return value, nil
+	}
+
+	expanded, err := vr.expand(value, []string{key})
+	if err != nil {
+		var fetchErr *fetchError
+		if errors.As(err, &fetchErr) {
+			return "", errorw.WrapErrorf(
+				ErrorCodeVarFetch, fetchErr.origin,
+				"Couldn't get variable %v referenced by %v", fetchErr.key, key)
+		}
+
+		return "", errorw.WrapErrorf(
+			ErrorCodeInterpolation, err, "Couldn't interpolate variable %v", key)
+	}
+
+	return expanded, nil
+}
+
+// Describe returns where the variable key is defined, according
+// to the provider if it implements Describer. Otherwise,
+// returns the provider type, e.g. *providers.EnvVariables
+func (vr *VarReader) Describe(key string) string {
+	if describer, ok := vr.provider.(Describer); ok {
+		return describer.Describe(key)
+	}
+
+	return fmt.Sprintf("%T", vr.provider)
+}
+
+// Enumerable tells if the provider implements Enumerator
+func (vr *VarReader) Enumerable() bool {
+	_, ok := vr.provider.(Enumerator)
+
+	return ok
+}
+
+// Keys returns the sorted keys of the variables that start with prefix.
+// Returns UnsupportedEnumerationError if the provider doesn't implement
+// Enumerator. Provider errors are wrapped with ErrorCodeVarFetch
+func (vr *VarReader) Keys(prefix string) ([]string, error) {
+	enumerator, ok := vr.provider.(Enumerator)
+	if !ok {
+		return nil, UnsupportedEnumerationError
+	}
+
+	keys, err := enumerator.Keys(prefix)
+	if err != nil {
+		return nil, errorw.WrapErrorf(
+			ErrorCodeVarFetch, err, "Couldn't list variables with prefix %v", prefix)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
 }
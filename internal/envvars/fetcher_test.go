@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envvars
+
+import (
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type enumerableProvider struct {
+	mapProvider
+}
+
+func (ep enumerableProvider) Keys(prefix string) ([]string, error) {
+	if prefix == "ERROR" {
+		return nil, errors.New("some error")
+	}
+
+	var keys []string
+	for key := range ep.mapProvider {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func TestKeys(t *testing.T) {
+	provider := enumerableProvider{mapProvider{
+		"POSTGRES_PORT": "5432",
+		"POSTGRES_HOST": "localhost",
+		"REDIS_ADDRS":   "localhost:6379",
+	}}
+
+	vr := New(provider)
+	if !vr.Enumerable() {
+		t.Error("Expecting enumerable reader")
+	}
+
+	keys, err := vr.Keys("POSTGRES_")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	} else if expected := []string{"POSTGRES_HOST", "POSTGRES_PORT"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+
+	_, err = vr.Keys("ERROR")
+	if errw, ok := err.(*errorw.Wrapper); !ok || errw.Code() != ErrorCodeVarFetch {
+		t.Errorf("Expecting error with code ErrorCodeVarFetch, got %v", err)
+	}
+}
+
+func TestUnsupportedKeys(t *testing.T) {
+	vr := New(mapProvider{})
+	if vr.Enumerable() {
+		t.Error("Expecting non enumerable reader")
+	}
+
+	if _, err := vr.Keys(""); err != UnsupportedEnumerationError {
+		t.Errorf("Expecting error UnsupportedEnumerationError, got %v", err)
+	}
+}
+
+type describedProvider struct {
+	mapProvider
+}
+
+func (dp describedProvider) Describe(key string) string {
+	return "map:" + key
+}
+
+func TestDescribe(t *testing.T) {
+	if source := New(describedProvider{}).Describe("HOST"); source != "map:HOST" {
+		t.Errorf("Expecting source map:HOST, got %v", source)
+	}
+
+	if source := New(mapProvider{}).Describe("HOST"); source != "envvars.mapProvider" {
+		t.Errorf("Expecting source envvars.mapProvider, got %v", source)
+	}
+}
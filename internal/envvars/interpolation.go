@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envvars
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Interpolation syntax
+const (
+	refPrefix         = "${"
+	refSuffix         = "}"
+	fallbackSeparator = ":-"
+	escapedDollar     = "$$"
+)
+
+// refNameRegex is used to validate referenced names
+var refNameRegex = regexp.MustCompile(`^[a-zA-Z_]\w*$`)
+
+// closingBrace returns the index of the brace that closes the reference
+// starting at the beginning of value, taking into account nested
+// references in fallbacks. Returns -1 if it isn't closed
+func closingBrace(value string) int {
+	depth := 0
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], escapedDollar):
+			i++
+		case strings.HasPrefix(value[i:], refPrefix):
+			depth++
+			i++
+		case value[i] == refSuffix[0]:
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// expand replaces each reference in value, where resolving
+// contains the variables being expanded to detect cycles
+func (vr *VarReader) expand(value string, resolving []string) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		rest := value[i:]
+
+		switch {
+		case strings.HasPrefix(rest, escapedDollar):
+			b.WriteByte('$')
+			i++
+		case strings.HasPrefix(rest, refPrefix):
+			end := closingBrace(rest)
+			if end == -1 {
+				return "", &InvalidInterpolationError{
+					rawValue: value,
+					reason:   "missing closing brace",
+				}
+			}
+
+			expanded, err := vr.expandRef(rest[len(refPrefix):end], resolving)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(expanded)
+			i += end
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandRef returns the value of a reference in the format NAME or
+// NAME:-fallback, where the fallback is used if NAME is empty
+func (vr *VarReader) expandRef(ref string, resolving []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(ref, fallbackSeparator)
+
+	if !refNameRegex.MatchString(name) {
+		return "", &InvalidInterpolationError{
+			rawValue: refPrefix + ref + refSuffix,
+			reason:   "invalid variable's name",
+		}
+	}
+
+	for i, resolvingName := range resolving {
+		if resolvingName == name {
+			cycle := append(append([]string{}, resolving[i:]...), name)
+
+			return "", &InterpolationCycleError{cycle: cycle}
+		}
+	}
+
+	value, err := vr.provider.Get(name)
+	if err != nil {
+		return "", &fetchError{key: name, origin: err}
+	}
+
+	nested := append(append(make([]string, 0, len(resolving)+1), resolving...), name)
+
+	if value, err = vr.expand(value, nested); err != nil {
+		return "", err
+	}
+
+	if value == "" && hasFallback {
+		return vr.expand(fallback, resolving)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envvars
+
+import (
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/errorw"
+	"testing"
+)
+
+type mapProvider map[string]string
+
+func (mp mapProvider) Get(key string) (string, error) {
+	if key == "ERROR" {
+		return "", errors.New("some error")
+	}
+
+	return mp[key], nil
+}
+
+func TestInterpolation(t *testing.T) {
+	provider := mapProvider{
+		"HOST":       "localhost",
+		"PORT":       "5432",
+		"ADDR":       "${HOST}:${PORT}",
+		"URL":        "postgres://${ADDR}/${DB:-users}",
+		"NESTED":     "${MISSING:-${HOST:-other}}",
+		"ESCAPED":    "pa$$word$${HOST}",
+		"LONE":       "cost: 5$ {HOST} $",
+		"EMPTY":      "${MISSING}",
+		"EMPTY_FALL": "${MISSING:-}",
+	}
+
+	reader := New(provider, WithInterpolation())
+
+	testBattery := []struct {
+		key      string
+		expected string
+	}{
+		{"HOST", "localhost"},
+		{"ADDR", "localhost:5432"},
+		{"URL", "postgres://localhost:5432/users"},
+		{"NESTED", "localhost"},
+		{"ESCAPED", "pa$word${HOST}"},
+		{"LONE", "cost: 5$ {HOST} $"},
+		{"EMPTY", ""},
+		{"EMPTY_FALL", ""},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			value, err := reader.Get(pair.key)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			if value != pair.expected {
+				t.Errorf("Expecting value %v, got %v", pair.expected, value)
+			}
+		})
+	}
+}
+
+func TestWithoutInterpolation(t *testing.T) {
+	reader := New(mapProvider{"A": "${B}$$"})
+
+	if value, _ := reader.Get("A"); value != "${B}$$" {
+		t.Errorf("Expecting raw value, got %v", value)
+	}
+}
+
+func TestInvalidInterpolation(t *testing.T) {
+	provider := mapProvider{
+		"SELF":     "${SELF}",
+		"A":        "${B}",
+		"B":        "x${C:-${A}}",
+		"C":        "",
+		"UNCLOSED": "${A",
+		"NAME":     "${1A}",
+		"FETCH":    "${ERROR}",
+	}
+
+	reader := New(provider, WithInterpolation())
+
+	testBattery := []struct {
+		key   string
+		code  errorw.ErrorCode
+		check func(err error) bool
+	}{
+		{"SELF", ErrorCodeInterpolation, func(err error) bool {
+			var cycleErr *InterpolationCycleError
+			return errors.As(err, &cycleErr) && cycleErr.Error() ==
+				"variables reference each other: SELF -> SELF"
+		}},
+		{"A", ErrorCodeInterpolation, func(err error) bool {
+			var cycleErr *InterpolationCycleError
+			return errors.As(err, &cycleErr) && cycleErr.Error() ==
+				"variables reference each other: A -> B -> A"
+		}},
+		{"UNCLOSED", ErrorCodeInterpolation, func(err error) bool {
+			var fmtErr *InvalidInterpolationError
+			return errors.As(err, &fmtErr)
+		}},
+		{"NAME", ErrorCodeInterpolation, func(err error) bool {
+			var fmtErr *InvalidInterpolationError
+			return errors.As(err, &fmtErr)
+		}},
+		{"FETCH", ErrorCodeVarFetch, func(err error) bool {
+			return err != nil
+		}},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			_, err := reader.Get(pair.key)
+
+			var wErr *errorw.Wrapper
+			if !errors.As(err, &wErr) || wErr.Code() != pair.code {
+				t.Errorf("Expecting error with code %v, got %v", pair.code, err)
+			}
+
+			if !pair.check(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
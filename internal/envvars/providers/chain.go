@@ -0,0 +1,198 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"fmt"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"path"
+)
+
+// describe returns where key is defined according to
+// provider, or its type if it doesn't implement Describer
+func describe(provider envvars.Provider, key string) string {
+	if describer, ok := provider.(envvars.Describer); ok {
+		return describer.Describe(key)
+	}
+
+	return fmt.Sprintf("%T", provider)
+}
+
+// enumerate returns the keys of provider that start with prefix.
+// Returns envvars.UnsupportedEnumerationError if it can't list them
+func enumerate(provider envvars.Provider, prefix string) ([]string, error) {
+	enumerator, ok := provider.(envvars.Enumerator)
+	if !ok {
+		return nil, envvars.UnsupportedEnumerationError
+	}
+
+	return enumerator.Keys(prefix)
+}
+
+// FilteredProvider restricts a provider to the keys that match
+// some pattern, e.g. only *_SECRET keys from a secrets layer
+type FilteredProvider struct {
+	provider envvars.Provider
+	patterns []string
+}
+
+// Filter returns a provider that only fetches the keys of provider
+// that match one of patterns, whose syntax is the one of path.Match,
+// e.g. *_SECRET. Other keys are undefined. It panics if some pattern
+// is malformed, since patterns are expected to be constants
+func Filter(provider envvars.Provider, patterns ...string) *FilteredProvider {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("providers: invalid key pattern %v: %v", pattern, err))
+		}
+	}
+
+	return &FilteredProvider{provider: provider, patterns: patterns}
+}
+
+// matches tells if key matches some pattern
+func (fp *FilteredProvider) matches(key string) bool {
+	for _, pattern := range fp.patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (fp *FilteredProvider) Get(key string) (string, error) {
+	if !fp.matches(key) {
+		return "", nil
+	}
+
+	return fp.provider.Get(key)
+}
+
+// Describe returns where key is defined according to the filtered provider
+func (fp *FilteredProvider) Describe(key string) string {
+	return describe(fp.provider, key)
+}
+
+// Keys returns the matching keys of the filtered provider that start
+// with prefix. Returns envvars.UnsupportedEnumerationError if it
+// doesn't implement envvars.Enumerator
+func (fp *FilteredProvider) Keys(prefix string) ([]string, error) {
+	keys, err := enumerate(fp.provider, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []string
+	for _, key := range keys {
+		if fp.matches(key) {
+			matching = append(matching, key)
+		}
+	}
+
+	return matching, nil
+}
+
+// ChainProvider fetches variables from several providers (layers) by
+// order of precedence, returning the first non-empty value. It doesn't
+// keep any state, so it's safe to use it concurrently if its layers are
+type ChainProvider struct {
+	layers []envvars.Provider
+}
+
+// Chain returns a provider whose layers are the given providers, from
+// the highest to the lowest precedence, e.g. Chain(flags, env, dotenv,
+// file, defaults). Layers can be restricted to some keys, see Filter
+func Chain(layers ...envvars.Provider) *ChainProvider {
+	return &ChainProvider{layers: layers}
+}
+
+// lookup returns the first non-empty value of key and the position of the
+// layer that defines it, which is -1 if none of them does. If a layer
+// returns an error, it's returned without asking the others, since their
+// values might have a lower precedence than the missing one
+func (cp *ChainProvider) lookup(key string) (string, int, error) {
+	for i, layer := range cp.layers {
+		value, err := layer.Get(key)
+		if err != nil {
+			return "", -1, fmt.Errorf("layer %v (%v): %w", i, describe(layer, key), err)
+		}
+
+		if value != "" {
+			return value, i, nil
+		}
+	}
+
+	return "", -1, nil
+}
+
+// Get returns the first non-empty value of key, by order of precedence.
+// If a layer returns an error, it's returned without asking the others
+func (cp *ChainProvider) Get(key string) (string, error) {
+	value, _, err := cp.lookup(key)
+
+	return value, err
+}
+
+// Layer returns the position of the layer that defines key, i.e. the one
+// whose value is returned by Get. Returns false if none of them does or if
+// some layer fails. Layers are asked again on each call, so concurrent
+// readers never see the answer of one another
+func (cp *ChainProvider) Layer(key string) (int, bool) {
+	_, i, err := cp.lookup(key)
+	if err != nil || i == -1 {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// Describe returns where key is defined according to the layer
+// that defines it, along with its position in the chain
+func (cp *ChainProvider) Describe(key string) string {
+	i, ok := cp.Layer(key)
+	if !ok {
+		return "chain"
+	}
+
+	return fmt.Sprintf("%v (layer %v)", describe(cp.layers[i], key), i)
+}
+
+// Keys returns the keys of all layers that start with prefix, without
+// repetitions. Returns envvars.UnsupportedEnumerationError if some layer
+// can't list its keys, since the ones it defines would be missing
+func (cp *ChainProvider) Keys(prefix string) ([]string, error) {
+	unique := make(map[string]bool)
+
+	var keys []string
+
+	for i, layer := range cp.layers {
+		layerKeys, err := enumerate(layer, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("layer %v (%T): %w", i, layer, err)
+		}
+
+		for _, key := range layerKeys {
+			if !unique[key] {
+				unique[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"github.com/franciscosbf/micro-dwarf/internal/envvars"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// staticProvider fetches variables from a map
+type staticProvider map[string]string
+
+func (sp staticProvider) Get(key string) (string, error) {
+	return sp[key], nil
+}
+
+// enumerableProvider also lists its variables
+type enumerableProvider struct {
+	staticProvider
+}
+
+func (ep enumerableProvider) Keys(prefix string) ([]string, error) {
+	var keys []string
+	for key := range ep.staticProvider {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// failingProvider returns an error on every call
+type failingProvider struct{}
+
+var errProvider = errors.New("unavailable")
+
+func (failingProvider) Get(string) (string, error) { return "", errProvider }
+
+func (failingProvider) Keys(string) ([]string, error) { return nil, errProvider }
+
+func TestChainPrecedence(t *testing.T) {
+	flags := staticProvider{"PORT": "8080"}
+	secrets := staticProvider{"DB_PASSWORD_SECRET": "s3cr3t", "PORT": "1"}
+	defaults := staticProvider{"PORT": "80", "HOST": "localhost", "DB_PASSWORD_SECRET": "default"}
+
+	chain := Chain(flags, Filter(secrets, "*_SECRET"), defaults)
+
+	testBattery := []struct {
+		key      string
+		expected string
+		layer    int
+		answered bool
+	}{
+		{"PORT", "8080", 0, true},
+		{"DB_PASSWORD_SECRET", "s3cr3t", 1, true},
+		{"HOST", "localhost", 2, true},
+		{"UNDEFINED", "", 0, false},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			if value, err := chain.Get(pair.key); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if value != pair.expected {
+				t.Errorf("Expecting var %v containing value %v, got %v", pair.key, pair.expected, value)
+			}
+
+			if layer, answered := chain.Layer(pair.key); answered != pair.answered || layer != pair.layer {
+				t.Errorf("Expecting layer %v (answered: %v), got %v (answered: %v)",
+					pair.layer, pair.answered, layer, answered)
+			}
+		})
+	}
+}
+
+func TestChainLayerFollowsValues(t *testing.T) {
+	overrides := staticProvider{"PORT": "8080"}
+	chain := Chain(overrides, staticProvider{"PORT": "80"})
+
+	if layer, _ := chain.Layer("PORT"); layer != 0 {
+		t.Errorf("Expecting var PORT answered by layer 0, got %v", layer)
+	}
+
+	delete(overrides, "PORT")
+
+	if layer, _ := chain.Layer("PORT"); layer != 1 {
+		t.Errorf("Expecting var PORT answered by layer 1, got %v", layer)
+	}
+
+	if layer, answered := Chain(failingProvider{}).Layer("PORT"); answered {
+		t.Errorf("Expecting var PORT not answered by a failing layer, got %v", layer)
+	}
+}
+
+func TestChainDescribe(t *testing.T) {
+	t.Setenv("TEST_CHAIN_HOST", "env-host")
+
+	path := writeTempFile(t, ".env", "TEST_CHAIN_HOST=file-host\nTEST_CHAIN_PORT=5432\n")
+	dotEnv, err := NewDotEnv(path)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	vReader := envvars.New(Chain(NewEnvVariables(), dotEnv))
+
+	testBattery := []struct {
+		key      string
+		expected string
+		source   string
+	}{
+		{"TEST_CHAIN_HOST", "env-host", "env (layer 0)"},
+		{"TEST_CHAIN_PORT", "5432", path + " (layer 1)"},
+		{"TEST_CHAIN_UNDEFINED", "", "chain"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			if value, _ := vReader.Get(pair.key); value != pair.expected {
+				t.Errorf("Expecting var %v containing value %v, got %v", pair.key, pair.expected, value)
+			}
+
+			if source := vReader.Describe(pair.key); source != pair.source {
+				t.Errorf("Expecting var %v described by %v, got %v", pair.key, pair.source, source)
+			}
+		})
+	}
+}
+
+func TestChainLayerError(t *testing.T) {
+	chain := Chain(enumerableProvider{staticProvider{"HOST": "localhost"}}, failingProvider{}, staticProvider{"PORT": "80"})
+
+	if value, err := chain.Get("HOST"); err != nil || value != "localhost" {
+		t.Errorf("Expecting value localhost without error, got %v and %v", value, err)
+	}
+
+	if _, err := chain.Get("PORT"); !errors.Is(err, errProvider) {
+		t.Errorf("Expecting error of layer 1, got %v", err)
+	}
+
+	if _, err := chain.Keys(""); !errors.Is(err, errProvider) {
+		t.Errorf("Expecting error of layer 1, got %v", err)
+	}
+}
+
+func TestChainKeys(t *testing.T) {
+	chain := Chain(
+		enumerableProvider{staticProvider{"APP_HOST": "a", "APP_PORT": "1", "OTHER": "b"}},
+		Filter(enumerableProvider{staticProvider{"APP_PORT": "2", "APP_KEY_SECRET": "d", "APP_USER": "e"}}, "*_SECRET"),
+	)
+
+	keys, err := chain.Keys("APP_")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	sort.Strings(keys)
+
+	if expected := []string{"APP_HOST", "APP_KEY_SECRET", "APP_PORT"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+}
+
+func TestChainKeysUnsupported(t *testing.T) {
+	testBattery := []struct {
+		name  string
+		layer envvars.Provider
+	}{
+		{"not enumerable", staticProvider{"APP_NOT_LISTED": "c"}},
+		{"filtered not enumerable", Filter(staticProvider{"APP_TOKEN_SECRET": "f"}, "*_SECRET")},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			chain := Chain(enumerableProvider{staticProvider{"APP_HOST": "a"}}, pair.layer)
+
+			if _, err := chain.Keys("APP_"); !errors.Is(err, envvars.UnsupportedEnumerationError) {
+				t.Errorf("Expecting error UnsupportedEnumerationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilterPatterns(t *testing.T) {
+	filtered := Filter(staticProvider{
+		"DB_PASSWORD_SECRET":      "a",
+		"DB_PASSWORD_SECRET_FILE": "b",
+		"DB_HOST":                 "c",
+		"TLS_CERT":                "d",
+	}, "*_SECRET", "*_SECRET_FILE", "TLS_?ERT")
+
+	testBattery := []struct {
+		key      string
+		expected string
+	}{
+		{"DB_PASSWORD_SECRET", "a"},
+		{"DB_PASSWORD_SECRET_FILE", "b"},
+		{"DB_HOST", ""},
+		{"TLS_CERT", "d"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			if value, _ := filtered.Get(pair.key); value != pair.expected {
+				t.Errorf("Expecting var %v containing value %q, got %q", pair.key, pair.expected, value)
+			}
+		})
+	}
+
+	if _, err := filtered.Keys(""); !errors.Is(err, envvars.UnsupportedEnumerationError) {
+		t.Errorf("Expecting error UnsupportedEnumerationError, got %v", err)
+	}
+}
+
+func TestFilterInvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expecting panic on invalid pattern")
+		}
+	}()
+
+	Filter(staticProvider{}, "[_SECRET")
+}
+
+func TestChainConcurrentGet(t *testing.T) {
+	chain := Chain(staticProvider{}, staticProvider{"TEST_5": "hi"})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if value, _ := chain.Get("TEST_5"); value != "hi" {
+				t.Errorf("Expecting var TEST_5 containing value hi, got %v", value)
+			}
+
+			if layer, _ := chain.Layer("TEST_5"); layer != 1 {
+				t.Errorf("Expecting var TEST_5 answered by layer 1, got %v", layer)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,446 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileFormat is the format of a config file
+type FileFormat int
+
+// Supported formats
+const (
+	// FormatAuto infers the format from the file extension
+	FormatAuto FileFormat = iota
+	FormatYAML
+	FormatJSON
+	FormatTOML
+)
+
+// fileFormats maps each file extension to its format
+var fileFormats = map[string]FileFormat{
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".json": FormatJSON,
+	".toml": FormatTOML,
+}
+
+// keyPathSeparator joins the keys of nested
+// values, e.g. postgres.pool.max_cons
+const keyPathSeparator = "."
+
+// KeyMapper returns the variable name of the value
+// located by the keys of path, e.g. [postgres pool]
+type KeyMapper func(path []string) string
+
+// configFileOptions contains the settings of ConfigFile
+type configFileOptions struct {
+	format        FileFormat
+	root          string
+	mapper        KeyMapper
+	keySeparator  string
+	listSeparator string
+	mapKeys       map[string]bool
+}
+
+// ConfigFileOption configures a ConfigFile
+type ConfigFileOption func(o *configFileOptions)
+
+// WithFormat sets the format of the file, instead of inferring it
+func WithFormat(format FileFormat) ConfigFileOption {
+	return func(o *configFileOptions) {
+		o.format = format
+	}
+}
+
+// WithRoot only maps the values under the key path root, e.g. config,
+// which isn't part of the variable names. It's useful to keep variables
+// in a section of a bigger file, like Helm values
+func WithRoot(root string) ConfigFileOption {
+	return func(o *configFileOptions) {
+		o.root = root
+	}
+}
+
+// WithKeySeparator sets the separator that joins the keys of a path
+// in variable names, which is an underscore by default
+func WithKeySeparator(sep string) ConfigFileOption {
+	return func(o *configFileOptions) {
+		o.keySeparator = sep
+	}
+}
+
+// WithKeyMapper replaces the mapping of key paths to variable names,
+// which by default converts each key to upper case, replaces characters
+// other than letters, digits and underscores by underscores and joins
+// them with the key separator, see WithKeySeparator
+func WithKeyMapper(mapper KeyMapper) ConfigFileOption {
+	return func(o *configFileOptions) {
+		o.mapper = mapper
+	}
+}
+
+// WithListSeparator sets the separator of the elements of lists and the
+// entries of maps, which is a comma by default like in config collections
+func WithListSeparator(sep string) ConfigFileOption {
+	return func(o *configFileOptions) {
+		o.listSeparator = sep
+	}
+}
+
+// WithMapKeys renders the maps at the given key paths (relative to the
+// root) as a single variable, whose entries are in the format key=value.
+// Otherwise, each map value is mapped to its own variable
+func WithMapKeys(keyPaths ...string) ConfigFileOption {
+	return func(o *configFileOptions) {
+		for _, keyPath := range keyPaths {
+			o.mapKeys[keyPath] = true
+		}
+	}
+}
+
+// defaultKeyMapper joins the normalized keys of path with sep
+func defaultKeyMapper(sep string) KeyMapper {
+	return func(path []string) string {
+		names := make([]string, len(path))
+
+		for i, key := range path {
+			names[i] = strings.Map(func(r rune) rune {
+				switch {
+				case r >= 'a' && r <= 'z':
+					return r - 'a' + 'A'
+				case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+					return r
+				default:
+					return '_'
+				}
+			}, key)
+		}
+
+		return strings.Join(names, sep)
+	}
+}
+
+// decodeConfigFile decodes content according to format
+func decodeConfigFile(content []byte, format FileFormat) (any, error) {
+	var tree any
+
+	switch format {
+	case FormatYAML:
+		if err := yaml.Unmarshal(content, &tree); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&tree); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if _, err := toml.Decode(string(content), &tree); err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+// renderScalar formats a scalar value as expected by
+// the config converters. Returns false if val isn't a scalar
+func renderScalar(val any) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int, int64, uint64:
+		return fmt.Sprintf("%d", v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case time.Time:
+		// Local TOML values don't have an offset, so they're kept as
+		// written. Their locations are named after their types
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format(time.DateOnly), true
+		case "time-local":
+			return v.Format("15:04:05.999999999"), true
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999"), true
+		}
+
+		return v.Format(time.RFC3339Nano), true
+	default:
+		return "", false
+	}
+}
+
+// configFileMapper maps the values of a config file to variables
+type configFileMapper struct {
+	file     string
+	opts     *configFileOptions
+	vars     map[string]string
+	keyPaths map[string]string
+}
+
+// fail returns ConfigFileError of the value at path
+func (m *configFileMapper) fail(path []string, reason string, args ...any) error {
+	return &ConfigFileError{
+		file:    m.file,
+		keyPath: strings.Join(path, keyPathSeparator),
+		reason:  fmt.Sprintf(reason, args...),
+	}
+}
+
+// keysOf returns the sorted keys of a decoded map
+func keysOf(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// asMap returns val as a map with string keys, if it's a map.
+// YAML maps can have keys of any type, which are formatted
+func asMap(val any) (map[string]any, bool) {
+	switch m := val.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		converted := make(map[string]any, len(m))
+		for key, elem := range m {
+			converted[fmt.Sprint(key)] = elem
+		}
+
+		return converted, true
+	default:
+		return nil, false
+	}
+}
+
+// render formats a list or a map at path
+// as a single value, whose elements are scalars
+func (m *configFileMapper) render(path []string, val any) (string, error) {
+	var elems []string
+
+	if entries, ok := asMap(val); ok {
+		for _, key := range keysOf(entries) {
+			elem, ok := renderScalar(entries[key])
+			if !ok {
+				return "", m.fail(append(path, key), "map values must be scalars")
+			}
+
+			elems = append(elems, key+"="+elem)
+		}
+	} else {
+		for i, entry := range val.([]any) {
+			elem, ok := renderScalar(entry)
+			if !ok {
+				return "", m.fail(append(path, strconv.Itoa(i)), "list elements must be scalars")
+			}
+
+			elems = append(elems, elem)
+		}
+	}
+
+	return strings.Join(elems, m.opts.listSeparator), nil
+}
+
+// add maps the value at path to a variable. Returns
+// ConfigFileError if another key path has the same name
+func (m *configFileMapper) add(path []string, val string) error {
+	name := m.opts.mapper(path)
+	keyPath := strings.Join(path, keyPathSeparator)
+
+	if other, ok := m.keyPaths[name]; ok {
+		return m.fail(path, "variable %v is also mapped from key %v", name, other)
+	}
+
+	m.vars[name] = val
+	m.keyPaths[name] = keyPath
+
+	return nil
+}
+
+// walk maps the values of the tree located by path. Null values are ignored
+func (m *configFileMapper) walk(path []string, val any) error {
+	if val == nil {
+		return nil
+	}
+
+	keyPath := strings.Join(path, keyPathSeparator)
+
+	if entries, ok := asMap(val); ok && !m.opts.mapKeys[keyPath] {
+		for _, key := range keysOf(entries) {
+			if err := m.walk(append(path[:len(path):len(path)], key), entries[key]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if rendered, ok := renderScalar(val); ok {
+		return m.add(path, rendered)
+	}
+
+	if _, isList := val.([]any); !isList {
+		if _, isMap := asMap(val); !isMap {
+			return m.fail(path, "unsupported value of type %T", val)
+		}
+	}
+
+	rendered, err := m.render(path, val)
+	if err != nil {
+		return err
+	}
+
+	return m.add(path, rendered)
+}
+
+// ConfigFile reads variables from a structured config file (YAML, JSON
+// or TOML), which is parsed only once. Each scalar is mapped to a variable
+// named after its key path, e.g. postgres.pool.max_cons to
+// POSTGRES_POOL_MAX_CONS, see WithKeyMapper. Lists are mapped to a single
+// variable, whose elements are joined by commas, e.g. the format expected
+// by utils.ParseAddrs. Maps are mapped to a variable per value, unless
+// they're given to WithMapKeys. It's safe to use it concurrently
+type ConfigFile struct {
+	path     string
+	vars     map[string]string
+	keyPaths map[string]string
+}
+
+// NewConfigFile parses the config file at path. Returns the error of
+// os.ReadFile if the file can't be read, UnknownFileFormatError if
+// its format isn't given and the extension is unknown or ConfigFileError
+// if it's invalid or has values that can't be mapped, e.g. lists of maps
+func NewConfigFile(path string, opts ...ConfigFileOption) (*ConfigFile, error) {
+	o := &configFileOptions{
+		keySeparator:  "_",
+		listSeparator: ",",
+		mapKeys:       make(map[string]bool),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.mapper == nil {
+		o.mapper = defaultKeyMapper(o.keySeparator)
+	}
+
+	if o.format == FormatAuto {
+		format, ok := fileFormats[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			return nil, &UnknownFileFormatError{file: path}
+		}
+
+		o.format = format
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := decodeConfigFile(content, o.format)
+	if err != nil {
+		return nil, &ConfigFileError{file: path, reason: err.Error()}
+	}
+
+	var rootPath []string
+	if o.root != "" {
+		rootPath = strings.Split(o.root, keyPathSeparator)
+	}
+
+	for i, key := range rootPath {
+		entries, ok := asMap(tree)
+		if !ok {
+			return nil, &ConfigFileError{
+				file:    path,
+				keyPath: strings.Join(rootPath[:i], keyPathSeparator),
+				reason:  "expecting a map",
+			}
+		}
+
+		tree = entries[key]
+	}
+
+	m := &configFileMapper{
+		file:     path,
+		opts:     o,
+		vars:     make(map[string]string),
+		keyPaths: make(map[string]string),
+	}
+
+	if tree != nil {
+		if _, ok := asMap(tree); !ok {
+			return nil, &ConfigFileError{file: path, keyPath: o.root, reason: "expecting a map"}
+		}
+
+		if err := m.walk(nil, tree); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ConfigFile{path: path, vars: m.vars, keyPaths: m.keyPaths}, nil
+}
+
+func (cf *ConfigFile) Get(key string) (string, error) {
+	return cf.vars[key], nil
+}
+
+// Describe returns the file path, along with
+// the key path that defines key, if any
+func (cf *ConfigFile) Describe(key string) string {
+	if keyPath, ok := cf.keyPaths[key]; ok {
+		return fmt.Sprintf("%v (%v)", cf.path, keyPath)
+	}
+
+	return cf.path
+}
+
+// Keys returns the keys of the variables that start with prefix
+func (cf *ConfigFile) Keys(prefix string) ([]string, error) {
+	var keys []string
+
+	for key := range cf.vars {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
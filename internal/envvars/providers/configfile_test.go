@@ -0,0 +1,281 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `
+postgres:
+  host: localhost
+  port: 5432
+  ssl-mode: disable
+  pool:
+    max_cons: 10
+    max_conn_lifetime: 1h
+    jitter: ~
+redis:
+  addrs:
+    - node1:6379
+    - "[::1]:6379"
+  read_only_slaves: true
+  ratio: 0.75
+  since: 2024-01-02T10:00:00Z
+labels:
+  team: core
+  tier: 1
+`
+
+const jsonConfig = `{
+  "postgres": {
+    "host": "localhost",
+    "port": 5432,
+    "ssl-mode": "disable",
+    "pool": {"max_cons": 10, "max_conn_lifetime": "1h", "jitter": null}
+  },
+  "redis": {
+    "addrs": ["node1:6379", "[::1]:6379"],
+    "read_only_slaves": true,
+    "ratio": 0.75,
+    "since": "2024-01-02T10:00:00Z"
+  },
+  "labels": {"team": "core", "tier": 1}
+}`
+
+const tomlConfig = `
+[postgres]
+host = "localhost"
+port = 5432
+ssl-mode = "disable"
+
+[postgres.pool]
+max_cons = 10
+max_conn_lifetime = "1h"
+
+[redis]
+addrs = ["node1:6379", "[::1]:6379"]
+read_only_slaves = true
+ratio = 0.75
+since = 2024-01-02T10:00:00Z
+
+[labels]
+team = "core"
+tier = 1
+`
+
+func TestConfigFileFormats(t *testing.T) {
+	expected := map[string]string{
+		"POSTGRES_HOST":                   "localhost",
+		"POSTGRES_PORT":                   "5432",
+		"POSTGRES_SSL_MODE":               "disable",
+		"POSTGRES_POOL_MAX_CONS":          "10",
+		"POSTGRES_POOL_MAX_CONN_LIFETIME": "1h",
+		"REDIS_ADDRS":                     "node1:6379,[::1]:6379",
+		"REDIS_READ_ONLY_SLAVES":          "true",
+		"REDIS_RATIO":                     "0.75",
+		"REDIS_SINCE":                     "2024-01-02T10:00:00Z",
+		"LABELS":                          "team=core,tier=1",
+	}
+
+	testBattery := []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", yamlConfig},
+		{"config.yml", yamlConfig},
+		{"config.json", jsonConfig},
+		{"config.toml", tomlConfig},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			configFile, err := NewConfigFile(writeTempFile(t, pair.name, pair.content), WithMapKeys("labels"))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(configFile.vars, expected) {
+				t.Errorf("Expecting variables %v, got %v", expected, configFile.vars)
+			}
+
+			if value, err := configFile.Get("POSTGRES_POOL_JITTER"); value != "" || err != nil {
+				t.Errorf("Expecting null value to be undefined, got %q and error %v", value, err)
+			}
+		})
+	}
+}
+
+func TestConfigFileMapping(t *testing.T) {
+	content := `
+image: app:1.0
+config:
+  postgres:
+    host: db
+    pool:
+      max-cons: 10
+`
+
+	testBattery := []struct {
+		name     string
+		opts     []ConfigFileOption
+		expected map[string]string
+	}{
+		{
+			"whole file",
+			nil,
+			map[string]string{
+				"IMAGE":                         "app:1.0",
+				"CONFIG_POSTGRES_HOST":          "db",
+				"CONFIG_POSTGRES_POOL_MAX_CONS": "10",
+			},
+		},
+		{
+			"root",
+			[]ConfigFileOption{WithRoot("config")},
+			map[string]string{"POSTGRES_HOST": "db", "POSTGRES_POOL_MAX_CONS": "10"},
+		},
+		{
+			"nested root",
+			[]ConfigFileOption{WithRoot("config.postgres"), WithKeySeparator("__")},
+			map[string]string{"HOST": "db", "POOL__MAX_CONS": "10"},
+		},
+		{
+			"missing root",
+			[]ConfigFileOption{WithRoot("values")},
+			map[string]string{},
+		},
+		{
+			"key mapper",
+			[]ConfigFileOption{
+				WithRoot("config"),
+				WithKeyMapper(func(path []string) string { return strings.Join(path, ".") }),
+			},
+			map[string]string{"postgres.host": "db", "postgres.pool.max-cons": "10"},
+		},
+		{
+			"map keys",
+			[]ConfigFileOption{WithRoot("config"), WithMapKeys("postgres.pool"), WithListSeparator(";")},
+			map[string]string{"POSTGRES_HOST": "db", "POSTGRES_POOL": "max-cons=10"},
+		},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			configFile, err := NewConfigFile(writeTempFile(t, "values.yaml", content), pair.opts...)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(configFile.vars, pair.expected) {
+				t.Errorf("Expecting variables %v, got %v", pair.expected, configFile.vars)
+			}
+		})
+	}
+}
+
+func TestConfigFileFormatOption(t *testing.T) {
+	configFile, err := NewConfigFile(writeTempFile(t, "config", `{"host": "db"}`), WithFormat(FormatJSON))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if value, _ := configFile.Get("HOST"); value != "db" {
+		t.Errorf("Expecting var HOST containing value db, got %v", value)
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	testBattery := []struct {
+		name    string
+		content string
+		opts    []ConfigFileOption
+		keyPath string
+	}{
+		{"invalid.json", `{"host": `, nil, ""},
+		{"invalid.toml", `host = `, nil, ""},
+		{"list.yaml", `[1, 2]`, nil, ""},
+		{"maps.yaml", "nodes:\n  - host: a\n", nil, "nodes.0"},
+		{"nested.yaml", "labels:\n  a:\n    b: c\n", []ConfigFileOption{WithMapKeys("labels")}, "labels.a"},
+		{"duplicated.yaml", "pool_max: 1\npool:\n  max: 2\n", nil, "pool_max"},
+		{"root.yaml", "config: 1\n", []ConfigFileOption{WithRoot("config.postgres")}, "config"},
+		{"scalar_root.yaml", "config: 1\n", []ConfigFileOption{WithRoot("config")}, "config"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.name, func(t *testing.T) {
+			_, err := NewConfigFile(writeTempFile(t, pair.name, pair.content), pair.opts...)
+
+			var fileErr *ConfigFileError
+			if !errors.As(err, &fileErr) {
+				t.Errorf("Expecting error ConfigFileError, got %v", err)
+				return
+			}
+
+			if fileErr.keyPath != pair.keyPath {
+				t.Errorf("Expecting error at key %q, got %v", pair.keyPath, err)
+			}
+		})
+	}
+}
+
+func TestConfigFileUnknownFormat(t *testing.T) {
+	_, err := NewConfigFile(writeTempFile(t, "config.ini", "host=db"))
+
+	var formatErr *UnknownFileFormatError
+	if !errors.As(err, &formatErr) {
+		t.Errorf("Expecting error UnknownFileFormatError, got %v", err)
+	}
+}
+
+func TestConfigFileDescribeAndKeys(t *testing.T) {
+	path := writeTempFile(t, "config.yaml", yamlConfig)
+
+	configFile, err := NewConfigFile(path)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if source, expected := configFile.Describe("POSTGRES_POOL_MAX_CONS"), path+" (postgres.pool.max_cons)"; source != expected {
+		t.Errorf("Expecting %v, got %v", expected, source)
+	}
+
+	if source := configFile.Describe("UNDEFINED"); source != path {
+		t.Errorf("Expecting %v, got %v", path, source)
+	}
+
+	keys, err := configFile.Keys("REDIS_")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	sort.Strings(keys)
+
+	expected := []string{"REDIS_ADDRS", "REDIS_RATIO", "REDIS_READ_ONLY_SLAVES", "REDIS_SINCE"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// dotEnvKeyRegex matches valid variable names
+var dotEnvKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dotEnvEscapes maps the escape sequences of double-quoted values
+var dotEnvEscapes = map[byte]string{
+	'n':  "\n",
+	'r':  "\r",
+	't':  "\t",
+	'"':  "\"",
+	'\'': "'",
+	'\\': "\\",
+}
+
+// dotEnvParser parses the content of a .env file
+type dotEnvParser struct {
+	file    string
+	content string
+	pos     int
+	line    int
+}
+
+// fail returns DotEnvSyntaxError at line
+func (p *dotEnvParser) fail(line int, reason string, args ...any) error {
+	return &DotEnvSyntaxError{file: p.file, line: line, reason: fmt.Sprintf(reason, args...)}
+}
+
+// next returns the current character, advancing to the following one
+func (p *dotEnvParser) next() byte {
+	c := p.content[p.pos]
+	p.pos++
+
+	if c == '\n' {
+		p.line++
+	}
+
+	return c
+}
+
+// skipBlanks advances over spaces and tabs
+func (p *dotEnvParser) skipBlanks() {
+	for p.pos < len(p.content) && (p.content[p.pos] == ' ' || p.content[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// restOfLine returns the remaining characters of the current
+// line, advancing to the beginning of the following one
+func (p *dotEnvParser) restOfLine() string {
+	end := strings.IndexByte(p.content[p.pos:], '\n')
+	if end == -1 {
+		end = len(p.content) - p.pos
+	}
+
+	rest := p.content[p.pos : p.pos+end]
+	p.pos += end
+
+	if p.pos < len(p.content) {
+		p.next()
+	}
+
+	return rest
+}
+
+// quoted returns the value enclosed by quote, which can span several
+// lines. Escape sequences are only replaced in double-quoted values
+func (p *dotEnvParser) quoted(quote byte) (string, error) {
+	start := p.line
+	p.next()
+
+	var b strings.Builder
+
+	for p.pos < len(p.content) {
+		c := p.next()
+
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && quote == '"' && p.pos < len(p.content):
+			if escaped, ok := dotEnvEscapes[p.content[p.pos]]; ok {
+				p.next()
+				b.WriteString(escaped)
+				continue
+			}
+
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.fail(start, "missing closing quote %c", quote)
+}
+
+// value returns the value of the current entry. Unquoted values end at
+// the end of line or at an inline comment, i.e. # preceded by a blank,
+// where spaced tells if the value is preceded by blanks
+func (p *dotEnvParser) value(spaced bool) (string, error) {
+	line := p.line
+
+	if p.pos < len(p.content) && (p.content[p.pos] == '"' || p.content[p.pos] == '\'') {
+		val, err := p.quoted(p.content[p.pos])
+		if err != nil {
+			return "", err
+		}
+
+		rest := strings.TrimSpace(p.restOfLine())
+		if rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", p.fail(line, "unexpected characters after quoted value: %v", rest)
+		}
+
+		return val, nil
+	}
+
+	val := p.restOfLine()
+	if spaced && strings.HasPrefix(val, "#") {
+		return "", nil
+	}
+
+	for i := 1; i < len(val); i++ {
+		if val[i] == '#' && (val[i-1] == ' ' || val[i-1] == '\t') {
+			val = val[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(val), nil
+}
+
+// parse returns the variables of the file. If a key
+// is repeated, the last value overrides the previous
+func (p *dotEnvParser) parse() (map[string]string, error) {
+	vars := make(map[string]string)
+
+	for p.pos < len(p.content) {
+		p.skipBlanks()
+
+		line := p.line
+		entry := p.content[p.pos:]
+
+		if entry == "" || entry[0] == '\n' || entry[0] == '#' {
+			p.restOfLine()
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(entry, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			p.pos += len("export")
+			p.skipBlanks()
+			entry = p.content[p.pos:]
+		}
+
+		sep := strings.IndexAny(entry, "=\n")
+		if sep == -1 || entry[sep] != '=' {
+			return nil, p.fail(line, "expecting entry in the format KEY=value")
+		}
+
+		key := strings.TrimRight(entry[:sep], " \t")
+		if !dotEnvKeyRegex.MatchString(key) {
+			return nil, p.fail(line, "invalid variable name %q", key)
+		}
+
+		p.pos += sep + 1
+		start := p.pos
+		p.skipBlanks()
+
+		val, err := p.value(p.pos > start)
+		if err != nil {
+			return nil, err
+		}
+
+		vars[key] = val
+	}
+
+	return vars, nil
+}
+
+// DotEnv reads variables from .env files, which are parsed only once.
+// Each line contains an entry KEY=value, optionally preceded by export.
+// Values can be enclosed by single quotes (taken literally) or double
+// quotes (where \n, \r, \t, \", \' and \\ are escaped), spanning several
+// lines. Otherwise, they end at the end of line or at an inline comment.
+// Lines starting with # are comments. It's safe to use it concurrently
+type DotEnv struct {
+	vars    map[string]string
+	sources map[string]string
+}
+
+// NewDotEnv parses the .env files by the given order, where variables
+// of a file override the ones of the previous files. Returns the error
+// of os.ReadFile if some file can't be read, or DotEnvSyntaxError if
+// it's bad formatted
+func NewDotEnv(paths ...string) (*DotEnv, error) {
+	de := &DotEnv{
+		vars:    make(map[string]string),
+		sources: make(map[string]string),
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		p := &dotEnvParser{
+			file:    path,
+			content: strings.ReplaceAll(string(content), "\r\n", "\n"),
+			line:    1,
+		}
+
+		vars, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+
+		for key, val := range vars {
+			de.vars[key] = val
+			de.sources[key] = path
+		}
+	}
+
+	return de, nil
+}
+
+func (de *DotEnv) Get(key string) (string, error) {
+	return de.vars[key], nil
+}
+
+// Describe returns the path of the file that defines key
+func (de *DotEnv) Describe(key string) string {
+	if path, ok := de.sources[key]; ok {
+		return path
+	}
+
+	return "dotenv"
+}
+
+// Keys returns the keys of the variables that start with prefix
+func (de *DotEnv) Keys(prefix string) ([]string, error) {
+	var keys []string
+
+	for key := range de.vars {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
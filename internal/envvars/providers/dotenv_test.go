@@ -0,0 +1,213 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// writeTempFile writes content to a new temporary file named name
+func writeTempFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Couldn't write file %v: %v", path, err)
+	}
+
+	return path
+}
+
+func TestDotEnvValues(t *testing.T) {
+	path := writeTempFile(t, ".env", `# Postgres
+POSTGRES_HOST=localhost
+export POSTGRES_PORT = 5432
+  POSTGRES_USER=admin # inline comment
+POSTGRES_DBNAME=
+POSTGRES_SSL_MODE= # only a comment
+POSTGRES_PASSWORD_SECRET='p@ss#word $HOME \n'
+REDIS_ADDRS="a:1,b:2" # cluster
+REDIS_USERNAME="say \"hi\"\tnow\\"
+REDIS_PASSWORD_SECRET=abc#def
+MULTILINE="first
+second"
+LITERAL='first
+second'
+export=yes
+EMPTY_QUOTES=""
+WINDOWS=crlf`+"\r\n"+`LAST=last`)
+
+	dotEnv, err := NewDotEnv(path)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	testBattery := []struct {
+		key      string
+		expected string
+	}{
+		{"POSTGRES_HOST", "localhost"},
+		{"POSTGRES_PORT", "5432"},
+		{"POSTGRES_USER", "admin"},
+		{"POSTGRES_DBNAME", ""},
+		{"POSTGRES_SSL_MODE", ""},
+		{"POSTGRES_PASSWORD_SECRET", `p@ss#word $HOME \n`},
+		{"REDIS_ADDRS", "a:1,b:2"},
+		{"REDIS_USERNAME", "say \"hi\"\tnow\\"},
+		{"REDIS_PASSWORD_SECRET", "abc#def"},
+		{"MULTILINE", "first\nsecond"},
+		{"LITERAL", "first\nsecond"},
+		{"export", "yes"},
+		{"EMPTY_QUOTES", ""},
+		{"WINDOWS", "crlf"},
+		{"LAST", "last"},
+		{"UNDEFINED", ""},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			if value, err := dotEnv.Get(pair.key); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if value != pair.expected {
+				t.Errorf("Expecting var %v containing value %q, got %q", pair.key, pair.expected, value)
+			}
+		})
+	}
+}
+
+func TestDotEnvSyntaxErrors(t *testing.T) {
+	testBattery := []struct {
+		content string
+		line    int
+	}{
+		{"A=1\nB", 2},
+		{"A=1\n\nexport B", 3},
+		{"1A=1", 1},
+		{"A B=1", 1},
+		{"=1", 1},
+		{"A=1\nB=\"unclosed\nC=3", 2},
+		{"A='unclosed", 1},
+		{"A=\"quoted\" trailing", 1},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.content, func(t *testing.T) {
+			_, err := NewDotEnv(writeTempFile(t, ".env", pair.content))
+
+			var syntaxErr *DotEnvSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Expecting error DotEnvSyntaxError, got %v", err)
+				return
+			}
+
+			if syntaxErr.line != pair.line {
+				t.Errorf("Expecting error at line %v, got %v", pair.line, err)
+			}
+		})
+	}
+}
+
+func TestDotEnvFilesOrder(t *testing.T) {
+	base := writeTempFile(t, ".env", "HOST=localhost\nPORT=5432\n")
+	local := writeTempFile(t, ".env.local", "PORT=5433\nUSER=dev\n")
+
+	dotEnv, err := NewDotEnv(base, local)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	testBattery := []struct {
+		key      string
+		expected string
+		source   string
+	}{
+		{"HOST", "localhost", base},
+		{"PORT", "5433", local},
+		{"USER", "dev", local},
+		{"UNDEFINED", "", "dotenv"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.key, func(t *testing.T) {
+			if value, _ := dotEnv.Get(pair.key); value != pair.expected {
+				t.Errorf("Expecting var %v containing value %v, got %v", pair.key, pair.expected, value)
+			}
+
+			if source := dotEnv.Describe(pair.key); source != pair.source {
+				t.Errorf("Expecting var %v described by %v, got %v", pair.key, pair.source, source)
+			}
+		})
+	}
+}
+
+func TestDotEnvMissingFile(t *testing.T) {
+	_, err := NewDotEnv(filepath.Join(t.TempDir(), ".env"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expecting error fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestDotEnvKeys(t *testing.T) {
+	dotEnv, err := NewDotEnv(writeTempFile(t, ".env", "TEST_KEYS_1=a\nTEST_KEYS_2=\nTEST_OTHER=b\n"))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	keys, err := dotEnv.Keys("TEST_KEYS_")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	sort.Strings(keys)
+
+	if expected := []string{"TEST_KEYS_1", "TEST_KEYS_2"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+}
+
+func TestDotEnvConcurrentGet(t *testing.T) {
+	dotEnv, err := NewDotEnv(writeTempFile(t, ".env", "TEST_5=hi"))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if value, _ := dotEnv.Get("TEST_5"); value != "hi" {
+				t.Errorf("Expecting var TEST_5 containing value hi, got %v", value)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"errors"
+	"fmt"
+)
+
+var NonPositiveIntervalError = errors.New("watch interval must be greater than zero")
+
+// DotEnvSyntaxError represents a bad formatted line of a .env file
+type DotEnvSyntaxError struct {
+	file   string
+	line   int
+	reason string
+}
+
+func (e *DotEnvSyntaxError) Error() string {
+	return fmt.Sprintf("%v:%v: %v", e.file, e.line, e.reason)
+}
+
+// UnknownFileFormatError represents a config
+// file whose format can't be inferred
+type UnknownFileFormatError struct {
+	file string
+}
+
+func (e *UnknownFileFormatError) Error() string {
+	return fmt.Sprintf(
+		"unknown format of file %v, expecting extension .yaml, .yml, .json or .toml", e.file)
+}
+
+// ConfigFileError represents a config file that can't be decoded
+// or whose value at keyPath (if any) can't be mapped to a variable
+type ConfigFileError struct {
+	file    string
+	keyPath string
+	reason  string
+}
+
+func (e *ConfigFileError) Error() string {
+	if e.keyPath == "" {
+		return fmt.Sprintf("%v: %v", e.file, e.reason)
+	}
+
+	return fmt.Sprintf("%v: key %v: %v", e.file, e.keyPath, e.reason)
+}
+
+// DuplicatedSecretError represents two secret
+// files that define the same variable
+type DuplicatedSecretError struct {
+	name         string
+	first, other string
+}
+
+func (e *DuplicatedSecretError) Error() string {
+	return fmt.Sprintf("variable %v is defined by files %v and %v", e.name, e.first, e.other)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretNameMapper returns the variable name of the secret file at
+// relPath, relative to the directory. Files are ignored if it's empty
+type SecretNameMapper func(relPath string) string
+
+// SecretsDirOption configures a SecretsDir
+type SecretsDirOption func(sd *SecretsDir)
+
+// WithSecretNameMapper replaces the mapping of secret files to variable
+// names, which by default is the file name, e.g. to map tls.crt to
+// REDIS_TLS_CERT_SECRET
+func WithSecretNameMapper(mapper SecretNameMapper) SecretsDirOption {
+	return func(sd *SecretsDir) {
+		sd.mapper = mapper
+	}
+}
+
+// defaultSecretName returns the file name of relPath
+func defaultSecretName(relPath string) string {
+	return filepath.Base(relPath)
+}
+
+// SecretsDir reads variables from a directory tree of secret files, like
+// the ones mounted by Kubernetes, where each file name is a variable name
+// and its content the value, without trailing newlines. Hidden files and
+// directories are ignored (e.g. ..data), but symbolic links to files are
+// followed. It's safe to use it concurrently
+type SecretsDir struct {
+	root   string
+	mapper SecretNameMapper
+
+	mutex sync.RWMutex
+	vars  map[string]string
+	files map[string]string
+}
+
+// NewSecretsDir reads the secret files under root. Returns the error of
+// the file system if some of them can't be read, or DuplicatedSecretError
+// if more than one file defines the same variable
+func NewSecretsDir(root string, opts ...SecretsDirOption) (*SecretsDir, error) {
+	sd := &SecretsDir{root: root, mapper: defaultSecretName}
+
+	for _, opt := range opts {
+		opt(sd)
+	}
+
+	if _, err := sd.Reload(); err != nil {
+		return nil, err
+	}
+
+	return sd, nil
+}
+
+// read returns the values of the secret files and their paths
+func (sd *SecretsDir) read() (map[string]string, map[string]string, error) {
+	vars := make(map[string]string)
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(sd.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != sd.root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Follows symbolic links, like the ones to ..data
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sd.root, path)
+		if err != nil {
+			return err
+		}
+
+		name := sd.mapper(relPath)
+		if name == "" {
+			return nil
+		}
+
+		if other, ok := files[name]; ok {
+			return &DuplicatedSecretError{name: name, first: other, other: path}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		vars[name] = strings.TrimRight(string(content), "\r\n")
+		files[name] = path
+
+		return nil
+	})
+
+	return vars, files, err
+}
+
+// Reload reads the secret files again, returning the sorted names of the
+// variables that were added, removed or changed. Returns the same errors
+// as NewSecretsDir, in which case the previous values are kept
+func (sd *SecretsDir) Reload() ([]string, error) {
+	vars, files, err := sd.read()
+	if err != nil {
+		return nil, err
+	}
+
+	sd.mutex.Lock()
+	prev := sd.vars
+	sd.vars, sd.files = vars, files
+	sd.mutex.Unlock()
+
+	var changed []string
+
+	for name, value := range vars {
+		if prevValue, ok := prev[name]; !ok || prevValue != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := vars[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+// Watch reloads the secret files at each interval, until ctx is done.
+// If some variable has changed or the reload fails, onChange receives
+// the result of Reload, e.g. to reload a config.Watcher afterwards.
+// Returns NonPositiveIntervalError if interval isn't greater than
+// zero, otherwise blocks and returns nil
+func (sd *SecretsDir) Watch(
+	ctx context.Context,
+	interval time.Duration,
+	onChange func(changed []string, err error),
+) error {
+	if interval <= 0 {
+		return NonPositiveIntervalError
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if changed, err := sd.Reload(); err != nil || len(changed) > 0 {
+			onChange(changed, err)
+		}
+	}
+}
+
+func (sd *SecretsDir) Get(key string) (string, error) {
+	sd.mutex.RLock()
+	defer sd.mutex.RUnlock()
+
+	return sd.vars[key], nil
+}
+
+// Describe returns the path of the secret file that defines key
+func (sd *SecretsDir) Describe(key string) string {
+	sd.mutex.RLock()
+	defer sd.mutex.RUnlock()
+
+	if path, ok := sd.files[key]; ok {
+		return path
+	}
+
+	return sd.root
+}
+
+// Keys returns the keys of the variables that start with prefix
+func (sd *SecretsDir) Keys(prefix string) ([]string, error) {
+	sd.mutex.RLock()
+	defer sd.mutex.RUnlock()
+
+	var keys []string
+
+	for key := range sd.vars {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeSecrets writes each file of the map under root,
+// creating its parent directories if needed
+func writeSecrets(t *testing.T, root string, files map[string]string) {
+	for relPath, content := range files {
+		path := filepath.Join(root, relPath)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("Couldn't create directory of %v: %v", path, err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Couldn't write file %v: %v", path, err)
+		}
+	}
+}
+
+// mountSecrets mimics a Kubernetes secret volume, where each file is a
+// link to ..data/<name> and ..data links to the directory of version
+func mountSecrets(t *testing.T, root, version string, files map[string]string) {
+	writeSecrets(t, filepath.Join(root, version), files)
+
+	tmpLink := filepath.Join(root, "..data_tmp")
+	if err := os.Symlink(version, tmpLink); err != nil {
+		t.Fatalf("Couldn't link version %v: %v", version, err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(root, "..data")); err != nil {
+		t.Fatalf("Couldn't replace ..data: %v", err)
+	}
+
+	for name := range files {
+		link := filepath.Join(root, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+
+		if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+			t.Fatalf("Couldn't link %v: %v", name, err)
+		}
+	}
+}
+
+func TestSecretsDirValues(t *testing.T) {
+	root := t.TempDir()
+	writeSecrets(t, root, map[string]string{
+		"POSTGRES_PASSWORD_SECRET":        "s3cr3t\n",
+		"REDIS_TLS_KEY_SECRET":            "-----BEGIN KEY-----\nabc\n-----END KEY-----\r\n\n",
+		"postgres/POSTGRES_USER_SECRET":   "admin",
+		"EMPTY_SECRET":                    "",
+		".hidden":                         "ignored",
+		".git/IGNORED_SECRET":             "ignored",
+		"nested/deeper/REDIS_USER_SECRET": "default\n",
+	})
+
+	secrets, err := NewSecretsDir(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	expected := map[string]string{
+		"POSTGRES_PASSWORD_SECRET": "s3cr3t",
+		"REDIS_TLS_KEY_SECRET":     "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"POSTGRES_USER_SECRET":     "admin",
+		"EMPTY_SECRET":             "",
+		"REDIS_USER_SECRET":        "default",
+	}
+
+	for key, value := range expected {
+		if got, err := secrets.Get(key); err != nil || got != value {
+			t.Errorf("Expecting var %v containing value %q, got %q and error %v", key, value, got, err)
+		}
+	}
+
+	for _, key := range []string{".hidden", "IGNORED_SECRET"} {
+		if got, _ := secrets.Get(key); got != "" {
+			t.Errorf("Expecting var %v to be ignored, got %q", key, got)
+		}
+	}
+
+	keys, _ := secrets.Keys("REDIS_")
+	sort.Strings(keys)
+
+	if expectedKeys := []string{"REDIS_TLS_KEY_SECRET", "REDIS_USER_SECRET"}; !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expecting keys %v, got %v", expectedKeys, keys)
+	}
+
+	if source, path := secrets.Describe("POSTGRES_USER_SECRET"), filepath.Join(root, "postgres", "POSTGRES_USER_SECRET"); source != path {
+		t.Errorf("Expecting %v, got %v", path, source)
+	}
+
+	if source := secrets.Describe("UNDEFINED"); source != root {
+		t.Errorf("Expecting %v, got %v", root, source)
+	}
+}
+
+func TestSecretsDirMounted(t *testing.T) {
+	root := t.TempDir()
+	mountSecrets(t, root, "..2024_01_01", map[string]string{"tls.crt": "cert\n", "tls.key": "key\n"})
+
+	tlsNames := map[string]string{
+		"tls.crt": "REDIS_TLS_CERT_SECRET",
+		"tls.key": "REDIS_TLS_KEY_SECRET",
+	}
+
+	secrets, err := NewSecretsDir(root, WithSecretNameMapper(func(relPath string) string {
+		return tlsNames[relPath]
+	}))
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	keys, _ := secrets.Keys("")
+	sort.Strings(keys)
+
+	if expected := []string{"REDIS_TLS_CERT_SECRET", "REDIS_TLS_KEY_SECRET"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+
+	if value, _ := secrets.Get("REDIS_TLS_CERT_SECRET"); value != "cert" {
+		t.Errorf("Expecting var REDIS_TLS_CERT_SECRET containing value cert, got %q", value)
+	}
+
+	// Kubernetes replaces ..data atomically on updates
+	mountSecrets(t, root, "..2024_02_01", map[string]string{"tls.crt": "new cert\n", "tls.key": "key\n"})
+
+	changed, err := secrets.Reload()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if expected := []string{"REDIS_TLS_CERT_SECRET"}; !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expecting changes %v, got %v", expected, changed)
+	}
+
+	if value, _ := secrets.Get("REDIS_TLS_CERT_SECRET"); value != "new cert" {
+		t.Errorf("Expecting var REDIS_TLS_CERT_SECRET containing value new cert, got %q", value)
+	}
+}
+
+func TestSecretsDirReload(t *testing.T) {
+	root := t.TempDir()
+	writeSecrets(t, root, map[string]string{"A_SECRET": "a", "B_SECRET": "b", "C_SECRET": "c"})
+
+	secrets, err := NewSecretsDir(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if changed, err := secrets.Reload(); err != nil || len(changed) != 0 {
+		t.Errorf("Expecting no changes, got %v and error %v", changed, err)
+	}
+
+	writeSecrets(t, root, map[string]string{"A_SECRET": "a\n", "B_SECRET": "new b", "D_SECRET": "d"})
+	_ = os.Remove(filepath.Join(root, "C_SECRET"))
+
+	changed, err := secrets.Reload()
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if expected := []string{"B_SECRET", "C_SECRET", "D_SECRET"}; !reflect.DeepEqual(changed, expected) {
+		t.Errorf("Expecting changes %v, got %v", expected, changed)
+	}
+
+	// Invalid trees keep the previous values
+	writeSecrets(t, root, map[string]string{"nested/B_SECRET": "other b"})
+
+	var duplicatedErr *DuplicatedSecretError
+	if _, err := secrets.Reload(); !errors.As(err, &duplicatedErr) {
+		t.Errorf("Expecting error DuplicatedSecretError, got %v", err)
+	}
+
+	if value, _ := secrets.Get("B_SECRET"); value != "new b" {
+		t.Errorf("Expecting var B_SECRET containing value new b, got %q", value)
+	}
+}
+
+func TestSecretsDirErrors(t *testing.T) {
+	root := t.TempDir()
+	writeSecrets(t, root, map[string]string{"a/HOST_SECRET": "a", "b/HOST_SECRET": "b"})
+
+	var duplicatedErr *DuplicatedSecretError
+	if _, err := NewSecretsDir(root); !errors.As(err, &duplicatedErr) {
+		t.Errorf("Expecting error DuplicatedSecretError, got %v", err)
+	}
+
+	if _, err := NewSecretsDir(filepath.Join(root, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expecting error fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestSecretsDirWatch(t *testing.T) {
+	root := t.TempDir()
+	writeSecrets(t, root, map[string]string{"A_SECRET": "a"})
+
+	secrets, err := NewSecretsDir(root)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	if err := secrets.Watch(context.Background(), 0, nil); err != NonPositiveIntervalError {
+		t.Errorf("Expecting error NonPositiveIntervalError, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notified := make(chan []string, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		_ = secrets.Watch(ctx, 10*time.Millisecond, func(changed []string, err error) {
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			select {
+			case notified <- changed:
+			default:
+			}
+		})
+	}()
+
+	writeSecrets(t, root, map[string]string{"A_SECRET": "new a"})
+
+	select {
+	case changed := <-notified:
+		if strings.Join(changed, ",") != "A_SECRET" {
+			t.Errorf("Expecting changes [A_SECRET], got %v", changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expecting notification of changes")
+	}
+
+	cancel()
+	wg.Wait()
+
+	if value, _ := secrets.Get("A_SECRET"); value != "new a" {
+		t.Errorf("Expecting var A_SECRET containing value new a, got %q", value)
+	}
+}
@@ -16,25 +16,81 @@ limitations under the License.
 
 package providers
 
-import "os"
+import (
+	"os"
+	"strings"
+	"sync"
+)
 
+// EnvVariables reads variables from the process environment. Values
+// are only cached if enabled with WithCache, so changes made in the
+// environment are seen on each read, e.g. by a config.Watcher reload.
+// It's safe to use it concurrently
 type EnvVariables struct {
+	mutex sync.RWMutex
 	cache map[string]string
 }
 
+// EnvVariablesOption configures an EnvVariables
+type EnvVariablesOption func(ev *EnvVariables)
+
+// WithCache keeps the value of each variable once it's read, for
+// the lifetime of the provider. Changes in the environment made
+// afterwards aren't seen, so it shouldn't be used for reloads
+func WithCache() EnvVariablesOption {
+	return func(ev *EnvVariables) {
+		ev.cache = make(map[string]string)
+	}
+}
+
 // NewEnvVariables creates a new env variables provider
-func NewEnvVariables() *EnvVariables {
-	varsCache := make(map[string]string)
+func NewEnvVariables(opts ...EnvVariablesOption) *EnvVariables {
+	ev := &EnvVariables{}
+	for _, opt := range opts {
+		opt(ev)
+	}
 
-	return &EnvVariables{cache: varsCache}
+	return ev
 }
 
 func (ev *EnvVariables) Get(key string) (string, error) {
-	if value, ok := ev.cache[key]; ok {
-		return value, nil
-	} else {
-		value = os.Getenv(key)
-		ev.cache[key] = value
+	if ev.cache == nil {
+		return os.Getenv(key), nil
+	}
+
+	ev.mutex.RLock()
+	value, ok := ev.cache[key]
+	ev.mutex.RUnlock()
+
+	if ok {
 		return value, nil
 	}
+
+	value = os.Getenv(key)
+
+	ev.mutex.Lock()
+	ev.cache[key] = value
+	ev.mutex.Unlock()
+
+	return value, nil
+}
+
+// Describe tells that every variable comes from the environment
+func (ev *EnvVariables) Describe(string) string {
+	return "env"
+}
+
+// Keys returns the keys of the env variables that start with prefix.
+// They're read directly from the environment, bypassing the cache, if any
+func (ev *EnvVariables) Keys(prefix string) ([]string, error) {
+	var keys []string
+
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
 }
@@ -18,6 +18,9 @@ package providers
 
 import (
 	"os"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 )
 
@@ -62,3 +65,68 @@ func TestVariableUnset(t *testing.T) {
 		t.Error("Expecting err to be nil")
 	}
 }
+
+func TestConcurrentGet(t *testing.T) {
+	t.Setenv("TEST_5", "hi")
+
+	envVars := NewEnvVariables(WithCache())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if value, _ := envVars.Get("TEST_5"); value != "hi" {
+				t.Errorf("Expecting var TEST_5 containing value hi, got %v", value)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestKeys(t *testing.T) {
+	t.Setenv("TEST_KEYS_1", "a")
+	t.Setenv("TEST_KEYS_2", "")
+	t.Setenv("TEST_OTHER", "b")
+
+	keys, err := NewEnvVariables().Keys("TEST_KEYS_")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+		return
+	}
+
+	sort.Strings(keys)
+
+	if expected := []string{"TEST_KEYS_1", "TEST_KEYS_2"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expecting keys %v, got %v", expected, keys)
+	}
+}
+
+func TestVariableChanged(t *testing.T) {
+	t.Setenv("TEST_6", "hi")
+
+	envVars := NewEnvVariables()
+	_, _ = envVars.Get("TEST_6")
+
+	t.Setenv("TEST_6", "bye")
+
+	if value, _ := envVars.Get("TEST_6"); value != "bye" {
+		t.Errorf("Expecting var TEST_6 containing value bye, got %v", value)
+	}
+}
+
+func TestCachedVariableChanged(t *testing.T) {
+	t.Setenv("TEST_7", "hi")
+
+	envVars := NewEnvVariables(WithCache())
+	_, _ = envVars.Get("TEST_7")
+
+	t.Setenv("TEST_7", "bye")
+
+	if value, _ := envVars.Get("TEST_7"); value != "hi" {
+		t.Errorf("Expecting var TEST_7 containing cached value hi, got %v", value)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secure
+
+// TlsConfig contains the elements of a secure client
+// connection. It's meant to be nested in a config struct
+// with a prefix, e.g. `prefix:"POSTGRES_"` results in the
+// variables POSTGRES_TLS, POSTGRES_TLS_HOSTNAME_SECRET, etc.
+// Secrets can be read from mounted files, by defining their
+// path instead, e.g. POSTGRES_TLS_CERT_SECRET_FILE=/tls/tls.crt
+type TlsConfig struct {
+	UseTls   bool   `name:"TLS"`                                    // Enables TLS with client certificate authentication
+	HostName string `name:"TLS_HOSTNAME_SECRET"`                    // Server name used to verify the server certificate
+	Cert     string `name:"TLS_CERT_SECRET" required_if:"TLS=true"` // Client certificate in PEM format
+	Key      string `name:"TLS_KEY_SECRET" required_if:"TLS=true"`  // Client private key in PEM format
+	CA       string `name:"TLS_CA_SECRET" required_if:"TLS=true"`   // CA certificates in PEM format
+}
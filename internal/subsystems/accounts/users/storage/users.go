@@ -58,10 +58,11 @@ type UserRegistration struct {
 	Name *UserName
 }
 
+// String omits the password, so it's safe to be logged
 func (ur *UserRegistration) String() string {
 	return fmt.Sprintf(
-		"UserRegistration[Username: %v, Email: %v, Password: %v, Phone: %v, Name: %v]",
-		ur.Username, ur.Email, ur.Password, ur.Phone, ur.Name)
+		"UserRegistration[Username: %v, Email: %v, Password: ******, Phone: %v, Name: %v]",
+		ur.Username, ur.Email, ur.Phone, ur.Name)
 }
 
 // UserLocation represents an optional location
@@ -40,6 +40,17 @@ type Addrs struct {
 	Bucket []*Address
 }
 
+// String returns the addresses in the
+// format accepted by ParseAddrs
+func (a *Addrs) String() string {
+	formatted := make([]string, len(a.Bucket))
+	for i, addr := range a.Bucket {
+		formatted[i] = net.JoinHostPort(addr.Host, addr.Port)
+	}
+
+	return strings.Join(formatted, ",")
+}
+
 // InvalidAddrsListError represents a bad formatted list of addresses
 var InvalidAddrsListError = errors.New(
 	"invalid format. expects host1:port1,host2:port2")
@@ -77,10 +88,8 @@ func (e *InvalidAddrError) Error() string {
 // that this doesn't check if the port is valid or the host ip
 // has a valid address
 func ParseAddrs(addrsList string) (*Addrs, error) {
-	addrsList = PolishString(addrsList)
-
-	if addrsList == "" || strings.HasPrefix(addrsList, ",") ||
-		strings.HasSuffix(addrsList, ",") {
+	rawAddrs, ok := SplitList(addrsList, ",")
+	if !ok {
 		return nil, InvalidAddrsListError
 	}
 
@@ -89,13 +98,7 @@ func ParseAddrs(addrsList string) (*Addrs, error) {
 
 	var addrs []*Address
 
-	for _, rawAddr := range strings.Split(addrsList, ",") {
-		rawAddr = PolishString(rawAddr)
-
-		if rawAddr == "" {
-			return nil, InvalidAddrsListError
-		}
-
+	for _, rawAddr := range rawAddrs {
 		if parsed.Contains(rawAddr) {
 			return nil, &DuplicatedAddrError{
 				rawAddr: rawAddr,
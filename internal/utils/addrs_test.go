@@ -141,3 +141,17 @@ func TestInvalidAddrsList(t *testing.T) {
 		t.Run(pair.name, pair.test)
 	}
 }
+
+func TestAddrsString(t *testing.T) {
+	raw := "localhost:123,[::1]:456"
+
+	addrs, err := ParseAddrs(raw)
+	if err != nil {
+		t.Errorf("Unexpected error %v", err)
+		return
+	}
+
+	if formatted := addrs.String(); formatted != raw {
+		t.Errorf("Expecting %v, got %v", raw, formatted)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize represents a number of bytes
+type ByteSize uint64
+
+// Decimal and binary units of ByteSize
+const (
+	Byte ByteSize = 1
+
+	KB = 1000 * Byte
+	MB = 1000 * KB
+	GB = 1000 * MB
+	TB = 1000 * GB
+	PB = 1000 * TB
+
+	KiB = 1024 * Byte
+	MiB = 1024 * KiB
+	GiB = 1024 * MiB
+	TiB = 1024 * GiB
+	PiB = 1024 * TiB
+)
+
+// byteUnits maps each unit symbol to its size,
+// sorted by the order used to format sizes
+var byteUnits = []struct {
+	symbol string
+	size   ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB}, {"kB", KB},
+	{"B", Byte},
+}
+
+// InvalidByteSizeError represents a bad formatted byte size
+var InvalidByteSizeError = errors.New(
+	"invalid format. expects an integer followed by an optional unit, e.g. 64MiB")
+
+// ByteSizeOverflowError represents a byte size that doesn't fit in 64 bits
+var ByteSizeOverflowError = errors.New("byte size is too large")
+
+// String returns the size in the format accepted by ParseByteSize,
+// using the largest unit that represents it without fractions
+func (s ByteSize) String() string {
+	if s == 0 {
+		return "0B"
+	}
+
+	for _, unit := range byteUnits {
+		if s%unit.size == 0 {
+			return strconv.FormatUint(uint64(s/unit.size), 10) + unit.symbol
+		}
+	}
+
+	return strconv.FormatUint(uint64(s), 10) + "B"
+}
+
+// ParseByteSize parses a non-negative integer followed by an optional
+// unit, which can be separated by spaces. Decimal units (kB or KB, MB,
+// GB, TB and PB) are powers of 1000 and binary ones (KiB, MiB, GiB, TiB
+// and PiB) are powers of 1024. Without unit, the value is in bytes.
+// Returns InvalidByteSizeError if rawSize is bad formatted or
+// ByteSizeOverflowError if it's greater than math.MaxUint64
+func ParseByteSize(rawSize string) (ByteSize, error) {
+	rawSize = PolishString(rawSize)
+
+	digits := strings.IndexFunc(rawSize, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if digits == -1 {
+		digits = len(rawSize)
+	}
+
+	number, symbol := rawSize[:digits], strings.TrimSpace(rawSize[digits:])
+	if number == "" {
+		return 0, InvalidByteSizeError
+	}
+
+	unitSize := Byte
+	if symbol != "" {
+		unitSize = 0
+
+		for _, unit := range byteUnits {
+			if unit.symbol == symbol {
+				unitSize = unit.size
+				break
+			}
+		}
+
+		if unitSize == 0 {
+			return 0, InvalidByteSizeError
+		}
+	}
+
+	// Only digits are left, so it can only fail by overflow
+	n, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return 0, ByteSizeOverflowError
+	}
+
+	if n > math.MaxUint64/uint64(unitSize) {
+		return 0, ByteSizeOverflowError
+	}
+
+	return ByteSize(n) * unitSize, nil
+}
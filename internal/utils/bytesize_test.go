@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidByteSize(t *testing.T) {
+	testBattery := []struct {
+		raw      string
+		expected ByteSize
+	}{
+		{"0", 0},
+		{"512", 512 * Byte},
+		{"512B", 512 * Byte},
+		{" 64 MiB ", 64 * MiB},
+		{"1kB", KB},
+		{"1KB", KB},
+		{"2GB", 2 * GB},
+		{"3TiB", 3 * TiB},
+		{"16PiB", 16 * PiB},
+		{"18446744073709551615", math.MaxUint64},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.raw, func(t *testing.T) {
+			size, err := ParseByteSize(pair.raw)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			} else if size != pair.expected {
+				t.Errorf("Expecting %v bytes, got %v", uint64(pair.expected), uint64(size))
+			}
+		})
+	}
+}
+
+func TestInvalidByteSize(t *testing.T) {
+	testBattery := []struct {
+		raw      string
+		expected error
+	}{
+		{"", InvalidByteSizeError},
+		{"MiB", InvalidByteSizeError},
+		{"-1MiB", InvalidByteSizeError},
+		{"1.5GiB", InvalidByteSizeError},
+		{"64mib", InvalidByteSizeError},
+		{"64 MiB B", InvalidByteSizeError},
+		{"16385PiB", ByteSizeOverflowError},
+		{"18446744073709551616", ByteSizeOverflowError},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.raw, func(t *testing.T) {
+			if _, err := ParseByteSize(pair.raw); err != pair.expected {
+				t.Errorf("Expecting error %v, got %v", pair.expected, err)
+			}
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	testBattery := []struct {
+		size     ByteSize
+		expected string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{64 * MiB, "64MiB"},
+		{1536 * KiB, "1536KiB"},
+		{5 * GB, "5GB"},
+		{1500 * Byte, "1500B"},
+	}
+
+	for _, pair := range testBattery {
+		t.Run(pair.expected, func(t *testing.T) {
+			if formatted := pair.size.String(); formatted != pair.expected {
+				t.Errorf("Expecting %v, got %v", pair.expected, formatted)
+			}
+
+			if parsed, _ := ParseByteSize(pair.expected); parsed != pair.size {
+				t.Errorf("Expecting %v to be parsed back, got %v", pair.expected, parsed)
+			}
+		})
+	}
+}
@@ -37,3 +37,12 @@ func SetAny(from any, to AnyPtr) {
 			Set(fromV)
 	}
 }
+
+// SetOptional assigns the value pointed by from to the
+// given pointer if from isn't nil. Unlike SetAny, zero
+// values are assigned, since nil means "not provided"
+func SetOptional[T any](from *T, to *T) {
+	if from != nil {
+		*to = *from
+	}
+}
@@ -68,3 +68,17 @@ func TestInvalidSetAny(t *testing.T) {
 		t.Run(pair.name, pair.test)
 	}
 }
+
+func TestSetOptional(t *testing.T) {
+	i, zero := 1, 0
+
+	SetOptional(nil, &i)
+	if i != 1 {
+		t.Errorf("Expecting value to be kept, got %v", i)
+	}
+
+	SetOptional(&zero, &i)
+	if i != 0 {
+		t.Errorf("Expecting zero value to be assigned, got %v", i)
+	}
+}
@@ -23,3 +23,59 @@ import "strings"
 func PolishString(s string) string {
 	return strings.Trim(s, " \t\n\v\f\r")
 }
+
+// SplitList splits a list of tokens separated by sep,
+// trimming each one. Returns false if the list is
+// empty or some token is empty, e.g. when the list
+// starts or ends with sep
+func SplitList(list, sep string) ([]string, bool) {
+	list = PolishString(list)
+	if list == "" {
+		return nil, false
+	}
+
+	tokens := strings.Split(list, sep)
+
+	for i, token := range tokens {
+		token = PolishString(token)
+		if token == "" {
+			return nil, false
+		}
+
+		tokens[i] = token
+	}
+
+	return tokens, true
+}
+
+// EditDistance returns the Levenshtein distance between a and b,
+// i.e. the minimum number of single character insertions,
+// deletions or substitutions that transform one into the other
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Distances between the prefixes of a and the current prefix of b
+	prev := make([]int, len(ra)+1)
+	curr := make([]int, len(ra)+1)
+
+	for i := range prev {
+		prev[i] = i
+	}
+
+	for j := 1; j <= len(rb); j++ {
+		curr[0] = j
+
+		for i := 1; i <= len(ra); i++ {
+			substitution := prev[i-1]
+			if ra[i-1] != rb[j-1] {
+				substitution++
+			}
+
+			curr[i] = min(prev[i]+1, curr[i-1]+1, substitution)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(ra)]
+}
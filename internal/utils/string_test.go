@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Francisco Simões Braço-Forte
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import "testing"
+
+func TestValidSplitList(t *testing.T) {
+	tokens, ok := SplitList("  a; b  ;\tc\n", ";")
+	if !ok {
+		t.Error("Expecting valid list")
+		return
+	}
+
+	expected := []string{"a", "b", "c"}
+
+	if len(tokens) != len(expected) {
+		t.Errorf("Expecting tokens %v, got %v", expected, tokens)
+		return
+	}
+
+	for i, token := range expected {
+		if tokens[i] != token {
+			t.Errorf("Expecting token %v at position %v, got %v", token, i, tokens[i])
+		}
+	}
+}
+
+func TestInvalidSplitList(t *testing.T) {
+	lists := []string{
+		"",
+		"   ",
+		",a,b",
+		"a,b,",
+		"a,  ,b",
+		"a,,b",
+	}
+
+	for _, l := range lists {
+		if tokens, ok := SplitList(l, ","); ok {
+			t.Errorf("Expecting list \"%v\" to be invalid, got %v", l, tokens)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	testBattery := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"POSTGRES_POOL_MAX_CONNS", "POSTGRES_POOL_MAX_CONS", 1},
+		{"POSTGRES_HSOT", "POSTGRES_HOST", 2},
+		{"kitten", "sitting", 3},
+		{"ção", "cão", 1},
+	}
+
+	for _, pair := range testBattery {
+		if distance := EditDistance(pair.a, pair.b); distance != pair.expected {
+			t.Errorf("Expecting distance %v between %v and %v, got %v",
+				pair.expected, pair.a, pair.b, distance)
+		}
+		if distance := EditDistance(pair.b, pair.a); distance != pair.expected {
+			t.Errorf("Expecting distance %v between %v and %v, got %v",
+				pair.expected, pair.b, pair.a, distance)
+		}
+	}
+}